@@ -0,0 +1,54 @@
+package nlp
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Converter converts the text read from an expression to a value
+type Converter func(s string) (interface{}, error)
+
+// WithConverter makes the fields of type t supported by the model,
+// their values are converted by conv which must return a value of
+// type t, it takes precedence over the conversions of nlp:
+//
+//	nlp.WithConverter(reflect.TypeOf(decimal.Decimal{}), func(s string) (interface{}, error) {
+//		return decimal.NewFromString(s)
+//	})
+func WithConverter(t reflect.Type, conv func(s string) (interface{}, error)) ModelOption {
+	return func(m *model) error {
+		if t == nil {
+			return errors.New("converter type can't be nil")
+		}
+		if conv == nil {
+			return fmt.Errorf("converter of %v can't be nil", t)
+		}
+		if m.converters == nil {
+			m.converters = make(map[reflect.Type]Converter)
+		}
+		m.converters[t] = conv
+		return nil
+	}
+}
+
+// convert sets s in fv using the converter of t, or
+// encoding.TextUnmarshaler if t doesn't have any
+func (m *model) convert(fv reflect.Value, t reflect.Type, s string) error {
+	if conv, ok := m.converters[t]; ok {
+		v, err := conv(s)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() || !rv.Type().AssignableTo(t) {
+			return fmt.Errorf("converter of %v returned %T", t, v)
+		}
+		fv.Set(rv)
+		return nil
+	}
+	return fv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+}
@@ -0,0 +1,353 @@
+package nlp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WithNow sets the func used to get the current time when parsing
+// relative dates like "tomorrow" or "in 3 days", the default is time.Now
+func WithNow(now func() time.Time) ModelOption {
+	return func(m *model) error {
+		if now == nil {
+			return errors.New("now can't be nil")
+		}
+		m.now = now
+		return nil
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// dateUnits are the units of relative dates, in days, months and years
+var dateUnits = map[string][3]int{
+	"day": {1, 0, 0}, "days": {1, 0, 0},
+	"week": {7, 0, 0}, "weeks": {7, 0, 0},
+	"fortnight": {14, 0, 0}, "fortnights": {14, 0, 0},
+	"month": {0, 1, 0}, "months": {0, 1, 0},
+	"year": {0, 0, 1}, "years": {0, 0, 1},
+}
+
+// clockUnits are the units of relative times
+var clockUnits = map[string]time.Duration{
+	"second": time.Second, "seconds": time.Second, "sec": time.Second, "secs": time.Second,
+	"minute": time.Minute, "minutes": time.Minute, "min": time.Minute, "mins": time.Minute,
+	"hour": time.Hour, "hours": time.Hour, "hr": time.Hour, "hrs": time.Hour,
+}
+
+// dateParser reads a date written in english, its fields
+// are set as the words of the date are read
+type dateParser struct {
+	words []string
+	now   time.Time
+	t     time.Time
+	// day is true if the day of t has been set
+	day bool
+	// clock is true if the time of day has been set
+	clock bool
+	// relative is true if t is relative to now, so
+	// its time of day doesn't default to midnight
+	relative bool
+	// evening is true if the time of day defaults to 8pm
+	evening bool
+}
+
+// parseNaturalTime parses the dates written in english, like
+// "tomorrow at 5pm", "next friday", "in 3 days", "noon" or "may 18 1999",
+// relative to now. Dates without a time of day are at midnight.
+// Weekdays refer to the next one, including today unless "next" is used
+func parseNaturalTime(s string, now time.Time) (time.Time, error) {
+	p := &dateParser{
+		words: strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " "))),
+		now:   now,
+		t:     now,
+	}
+	if len(p.words) == 0 {
+		return time.Time{}, fmt.Errorf("can't parse %q as a date", s)
+	}
+	for i := 0; i < len(p.words); {
+		n := p.read(i)
+		if n == 0 {
+			return time.Time{}, fmt.Errorf("can't parse %q as a date", s)
+		}
+		i += n
+	}
+	switch {
+	case p.clock || p.relative:
+	case p.evening:
+		p.setClock(20, 0, 0)
+	default:
+		p.setClock(0, 0, 0)
+	}
+	return p.t, nil
+}
+
+// word returns the word i or an empty string if there isn't any
+func (p *dateParser) word(i int) string {
+	if i < len(p.words) {
+		return p.words[i]
+	}
+	return ""
+}
+
+// read reads the words starting at i and returns how
+// many of them were read, 0 if they aren't a date
+func (p *dateParser) read(i int) int {
+	w := p.word(i)
+	switch w {
+	case "at", "on", "of", "the":
+		return 1
+	case "now":
+		p.relative = true
+		return 1
+	case "today":
+		return p.setDay(p.now, 1)
+	case "tonight":
+		p.evening = true
+		return p.setDay(p.now, 1)
+	case "tomorrow":
+		return p.setDay(p.now.AddDate(0, 0, 1), 1)
+	case "yesterday":
+		return p.setDay(p.now.AddDate(0, 0, -1), 1)
+	case "noon", "midday":
+		return p.setClock(12, 0, 1)
+	case "midnight":
+		return p.setClock(0, 0, 1)
+	case "this", "next", "last":
+		next := p.word(i + 1)
+		if wd, ok := weekdays[next]; ok {
+			return p.setWeekday(wd, w, 2)
+		}
+		if u, ok := dateUnits[next]; ok && w != "this" {
+			sign := 1
+			if w == "last" {
+				sign = -1
+			}
+			return p.setDay(p.now.AddDate(sign*u[2], sign*u[1], sign*u[0]), 2)
+		}
+		return 0
+	case "in":
+		n, ok := dateAmount(p.word(i + 1))
+		if !ok {
+			return 0
+		}
+		read := p.add(n, p.word(i+2))
+		if read == 0 {
+			return 0
+		}
+		return read + 2
+	}
+	if wd, ok := weekdays[w]; ok {
+		return p.setWeekday(wd, "this", 1)
+	}
+	if mo, ok := months[w]; ok {
+		// may 18, may 18th 1999
+		d, ok := ordinal(p.word(i + 1))
+		if !ok {
+			return 0
+		}
+		return p.setDate(mo, d, i+2, 2)
+	}
+	if d, ok := ordinal(w); ok {
+		// 18 may, 18th of may 1999
+		j := i + 1
+		if p.word(j) == "of" {
+			j++
+		}
+		if mo, ok := months[p.word(j)]; ok {
+			return p.setDate(mo, d, j+1, j+1-i)
+		}
+	}
+	if n, ok := dateAmount(w); ok {
+		// 3 days ago, 2 hours from now
+		next := p.word(i + 1)
+		_, isDate := dateUnits[next]
+		_, isClock := clockUnits[next]
+		if isDate || isClock {
+			switch {
+			case p.word(i+2) == "ago":
+				if p.add(-n, next) == 0 {
+					return 0
+				}
+				return 3
+			case p.word(i+2) == "from" && p.word(i+3) == "now":
+				if p.add(n, next) == 0 {
+					return 0
+				}
+				return 4
+			}
+			return 0
+		}
+	}
+	return p.readClock(i)
+}
+
+// readClock reads times of day like 5pm, 5 pm, 5:30pm, 17:00 or 5 o'clock
+func (p *dateParser) readClock(i int) int {
+	w := p.word(i)
+	read := 1
+	suffix := ""
+	for _, s := range []string{"am", "pm", "a.m.", "p.m."} {
+		if strings.HasSuffix(w, s) && len(w) > len(s) {
+			w, suffix = strings.TrimSuffix(w, s), s[:1]
+			break
+		}
+	}
+	if suffix == "" {
+		switch p.word(i + 1) {
+		case "am", "a.m.":
+			suffix, read = "a", 2
+		case "pm", "p.m.":
+			suffix, read = "p", 2
+		case "o'clock":
+			read = 2
+		}
+	}
+	hs, ms, hasMin := strings.Cut(w, ":")
+	if !hasMin && suffix == "" && read == 1 {
+		// a bare number isn't a time of day
+		return 0
+	}
+	h, err := strconv.Atoi(hs)
+	if err != nil || h < 0 || h > 23 {
+		return 0
+	}
+	min := 0
+	if hasMin {
+		min, err = strconv.Atoi(ms)
+		if err != nil || len(ms) != 2 || min > 59 {
+			return 0
+		}
+	}
+	if suffix != "" {
+		if h < 1 || h > 12 {
+			return 0
+		}
+		h %= 12
+		if suffix == "p" {
+			h += 12
+		}
+	}
+	return p.setClock(h, min, read)
+}
+
+// setDay sets the day of d and returns read
+func (p *dateParser) setDay(d time.Time, read int) int {
+	if p.day {
+		return 0
+	}
+	p.day = true
+	p.t = time.Date(d.Year(), d.Month(), d.Day(), p.t.Hour(), p.t.Minute(), p.t.Second(), p.t.Nanosecond(), p.t.Location())
+	return read
+}
+
+// setClock sets the time of day and returns read
+func (p *dateParser) setClock(h, min, read int) int {
+	if p.clock {
+		return 0
+	}
+	p.clock = true
+	p.t = time.Date(p.t.Year(), p.t.Month(), p.t.Day(), h, min, 0, 0, p.t.Location())
+	return read
+}
+
+// setWeekday sets the day to the weekday wd after now,
+// including today unless mod is "next", or before now
+// if mod is "last"
+func (p *dateParser) setWeekday(wd time.Weekday, mod string, read int) int {
+	diff := int(wd - p.now.Weekday())
+	switch mod {
+	case "this":
+		if diff < 0 {
+			diff += 7
+		}
+	case "next":
+		if diff <= 0 {
+			diff += 7
+		}
+	case "last":
+		if diff >= 0 {
+			diff -= 7
+		}
+	}
+	return p.setDay(p.now.AddDate(0, 0, diff), read)
+}
+
+// setDate sets the day d of month mo, the year is read from
+// the word i if it has 4 digits, otherwise it's the current one
+func (p *dateParser) setDate(mo time.Month, d, i, read int) int {
+	y := p.now.Year()
+	if w := p.word(i); len(w) == 4 {
+		if v, err := strconv.Atoi(w); err == nil {
+			y, read = v, read+1
+		}
+	}
+	t := time.Date(y, mo, d, 0, 0, 0, 0, p.now.Location())
+	if t.Month() != mo {
+		// the day doesn't exist in the month
+		return 0
+	}
+	return p.setDay(t, read)
+}
+
+// add adds n units to the date and returns how many words
+// were read, 0 if unit isn't a unit or the date has been set
+func (p *dateParser) add(n int, unit string) int {
+	if p.day || p.relative {
+		return 0
+	}
+	if u, ok := dateUnits[unit]; ok {
+		p.t = p.t.AddDate(n*u[2], n*u[1], n*u[0])
+		p.day = true
+		p.relative = true
+		return 1
+	}
+	if u, ok := clockUnits[unit]; ok && !p.clock {
+		p.t = p.t.Add(time.Duration(n) * u)
+		p.day, p.clock, p.relative = true, true, true
+		return 1
+	}
+	return 0
+}
+
+// dateAmount returns the amount w represents in relative dates
+func dateAmount(w string) (int, bool) {
+	n, ok := parseNumber(w)
+	return int(n), ok && n >= 0 && n == math.Trunc(n)
+}
+
+// ordinal returns the day of the month w represents, like 18 or 18th
+func ordinal(w string) (int, bool) {
+	for _, s := range []string{"st", "nd", "rd", "th"} {
+		w = strings.TrimSuffix(w, s)
+	}
+	d, err := strconv.Atoi(w)
+	return d, err == nil && d >= 1 && d <= 31
+}
@@ -0,0 +1,93 @@
+package nlp
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNaturalTime(t *testing.T) {
+	// wednesday
+	now := time.Date(2017, 5, 17, 10, 30, 0, 0, time.UTC)
+	day := func(d, h, min int) time.Time {
+		return time.Date(2017, 5, d, h, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		expr    string
+		want    time.Time
+		wantErr bool
+	}{
+		{"now", now, false},
+		{"today", day(17, 0, 0), false},
+		{"tonight", day(17, 20, 0), false},
+		{"tonight at 9pm", day(17, 21, 0), false},
+		{"tomorrow", day(18, 0, 0), false},
+		{"yesterday", day(16, 0, 0), false},
+		{"noon", day(17, 12, 0), false},
+		{"midnight", day(17, 0, 0), false},
+		{"tomorrow at 5pm", day(18, 17, 0), false},
+		{"5:30 pm tomorrow", day(18, 17, 30), false},
+		{"tomorrow at 17:45", day(18, 17, 45), false},
+		{"at 7 o'clock", day(17, 7, 0), false},
+		{"12am", day(17, 0, 0), false},
+		{"friday", day(19, 0, 0), false},
+		{"wednesday", day(17, 0, 0), false},
+		{"this wed", day(17, 0, 0), false},
+		{"next wednesday", day(24, 0, 0), false},
+		{"next friday at noon", day(19, 12, 0), false},
+		{"last monday", day(15, 0, 0), false},
+		{"next week", day(24, 0, 0), false},
+		{"last month", time.Date(2017, 4, 17, 0, 0, 0, 0, time.UTC), false},
+		{"in 3 days", day(20, 10, 30), false},
+		{"in 3 days at 9am", day(20, 9, 0), false},
+		{"in an hour", day(17, 11, 30), false},
+		{"in three days", day(20, 10, 30), false},
+		{"in 2 weeks", day(31, 10, 30), false},
+		{"2 days ago", day(15, 10, 30), false},
+		{"90 minutes from now", day(17, 12, 0), false},
+		{"may 18", day(18, 0, 0), false},
+		{"May 18th, 1999", time.Date(1999, 5, 18, 0, 0, 0, 0, time.UTC), false},
+		{"18th of may 1999 at 6pm", time.Date(1999, 5, 18, 18, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"someday", time.Time{}, true},
+		{"tomorrow yesterday", time.Time{}, true},
+		{"5pm 6pm", time.Time{}, true},
+		{"in 3 hours at noon", time.Time{}, true},
+		{"february 30", time.Time{}, true},
+		{"13pm", time.Time{}, true},
+		{"42", time.Time{}, true},
+		{"3 days", time.Time{}, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseNaturalTime(tt.expr, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("[%d] parseNaturalTime() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("[%d] parseNaturalTime() = %v, want %v", i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithNow(t *testing.T) {
+	type T struct {
+		When time.Time
+	}
+
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	// 2am in UTC is still the day before in loc
+	now := func() time.Time { return time.Date(2017, 5, 18, 2, 0, 0, 0, time.UTC) }
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{"remind me {When}"}, WithNow(now), WithTimeLocation(loc)))
+	failTest(t, nl.Learn())
+
+	want := &T{When: time.Date(2017, 5, 18, 17, 0, 0, 0, loc)}
+	if res := nl.P("remind me tomorrow at 5pm"); !reflect.DeepEqual(res, want) {
+		t.Errorf("got %+v want %+v", res, want)
+	}
+	if err := WithNow(nil)(&model{}); err == nil {
+		t.Error("WithNow(nil) should fail")
+	}
+}
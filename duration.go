@@ -0,0 +1,113 @@
+package nlp
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// durationUnits are the units of durations written in english
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+	"us": time.Microsecond, "µs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour,
+	"week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// parseDuration parses the durations accepted by time.ParseDuration
+// and the ones written in english, like "two hours and ten minutes",
+// "90 min", "1.5 hours", "half an hour" or "an hour and a half"
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+	// 4h 2m
+	if d, err := time.ParseDuration(strings.Join(strings.Fields(s), "")); err == nil {
+		return d, nil
+	}
+	words := durationWords(s)
+	if len(words) == 0 {
+		return 0, fmt.Errorf("can't parse %q as a duration", s)
+	}
+	var d time.Duration
+	for i := 0; i < len(words); {
+		if words[i] == "and" && i > 0 && i+1 < len(words) {
+			i++
+			continue
+		}
+		amount, j := 0.0, i
+		if words[i] == "half" && (wordAt(words, i+1) == "a" || wordAt(words, i+1) == "an") {
+			// half an hour
+			amount, j = 0.5, i+2
+		} else {
+			for j < len(words) && durationUnits[words[j]] == 0 {
+				j++
+			}
+			v, ok := parseNumber(strings.Join(words[i:j], " "))
+			if !ok {
+				return 0, fmt.Errorf("can't parse %q as a duration", s)
+			}
+			amount = v
+		}
+		unit := durationUnits[wordAt(words, j)]
+		if unit == 0 {
+			return 0, fmt.Errorf("can't parse %q as a duration", s)
+		}
+		v := amount * float64(unit)
+		if v >= math.MaxInt64 || v < math.MinInt64 {
+			return 0, fmt.Errorf("duration %q is out of range", s)
+		}
+		var ok bool
+		if d, ok = addDuration(d, time.Duration(v)); !ok {
+			return 0, fmt.Errorf("duration %q is out of range", s)
+		}
+		i = j + 1
+		// an hour and a half
+		if wordAt(words, i) == "and" && wordAt(words, i+1) == "a" && wordAt(words, i+2) == "half" {
+			if d, ok = addDuration(d, unit/2); !ok {
+				return 0, fmt.Errorf("duration %q is out of range", s)
+			}
+			i += 3
+		}
+	}
+	return d, nil
+}
+
+// addDuration returns d+v, it returns false if the sum overflows
+func addDuration(d, v time.Duration) (time.Duration, bool) {
+	if v > 0 && d > math.MaxInt64-v || v < 0 && d < math.MinInt64-v {
+		return 0, false
+	}
+	return d + v, true
+}
+
+// durationWords splits s in lower case words, the
+// numbers followed by a unit, like 90min, are split too
+func durationWords(s string) []string {
+	var words []string
+	for _, w := range strings.Fields(strings.ToLower(strings.ReplaceAll(s, ",", " "))) {
+		if i := strings.IndexFunc(w, unicode.IsLetter); i > 0 && unicode.IsDigit(rune(w[0])) {
+			if _, ok := durationUnits[w[i:]]; ok {
+				words = append(words, w[:i], w[i:])
+				continue
+			}
+		}
+		words = append(words, w)
+	}
+	return words
+}
+
+// wordAt returns the word i of words or an empty string if there isn't any
+func wordAt(words []string, i int) string {
+	if i < len(words) {
+		return words[i]
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package nlp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"4h2m", 4*time.Hour + 2*time.Minute, false},
+		{"4h 2m", 4*time.Hour + 2*time.Minute, false},
+		{"1.5h", 90 * time.Minute, false},
+		{"90min", 90 * time.Minute, false},
+		{"90 min", 90 * time.Minute, false},
+		{"1.5 hours", 90 * time.Minute, false},
+		{"two hours and ten minutes", 2*time.Hour + 10*time.Minute, false},
+		{"2 hrs, 10 mins", 2*time.Hour + 10*time.Minute, false},
+		{"half an hour", 30 * time.Minute, false},
+		{"an hour and a half", 90 * time.Minute, false},
+		{"two and a half hours", 150 * time.Minute, false},
+		{"twenty five seconds", 25 * time.Second, false},
+		{"a day", 24 * time.Hour, false},
+		{"one hundred and five seconds", 105 * time.Second, false},
+		{"3 weeks", 21 * 24 * time.Hour, false},
+		{"500 ms", 500 * time.Millisecond, false},
+		{"", 0, true},
+		{"forever", 0, true},
+		{"two", 0, true},
+		{"hours", 0, true},
+		{"two hours and", 0, true},
+		{"two parsecs", 0, true},
+		{"2562048h", 0, true},
+		{"3000000 hours", 0, true},
+		{"a trillion hours", 0, true},
+		{"minus 3000000 hours", 0, true},
+		{"2562047 hours and 2562047 hours", 0, true},
+		{"15250 weeks and a half", 0, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := parseDuration(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("[%d] parseDuration() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("[%d] parseDuration() = %v, want %v", i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_DurationOutOfRange(t *testing.T) {
+	type Wait struct {
+		Dur time.Duration
+	}
+	nl := New()
+	failTest(t, nl.RegisterModel(Wait{}, []string{"wait {Dur}"}))
+	failTest(t, nl.Learn())
+
+	r, err := nl.Parse("wait 3000000 hours")
+	if _, ok := err.(FieldErrors); !ok {
+		t.Errorf("NL.Parse() error = %v, want FieldErrors", err)
+	}
+	if r == nil || r.Value.(*Wait).Dur != 0 {
+		t.Errorf("NL.Parse() = %+v, want a zero duration", r)
+	}
+}
@@ -0,0 +1,130 @@
+package nlp
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNotAllowed is the error of a *FieldError when the
+// value of an enum field isn't any of its allowed values
+var ErrNotAllowed = errors.New("value isn't allowed")
+
+// enum restricts the values of a field
+type enum struct {
+	values []string
+	// synonyms maps the lower case synonyms to their value
+	synonyms map[string]string
+}
+
+// WithEnum restricts the values of the field with the given keyword
+// to values, synonyms maps other words to one of the values:
+//
+//	nlp.WithEnum("Room", []string{"kitchen", "bedroom"}, map[string]string{"bed room": "bedroom"})
+//
+// The values are case insensitive, if the value read from an expression
+// isn't allowed the longest allowed value or synonym inside it is used
+func WithEnum(keyword string, values []string, synonyms map[string]string) ModelOption {
+	return func(m *model) error {
+		e, err := newEnum(values, synonyms)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", keyword, err)
+		}
+		if m.enums == nil {
+			m.enums = make(map[string]*enum)
+		}
+		m.enums[keyword] = e
+		return nil
+	}
+}
+
+func newEnum(values []string, synonyms map[string]string) (*enum, error) {
+	if len(values) == 0 {
+		return nil, errors.New("enum values can't be empty")
+	}
+	e := &enum{values: values, synonyms: make(map[string]string, len(synonyms))}
+	for _, v := range values {
+		if strings.TrimSpace(v) == "" {
+			return nil, errors.New("enum values can't be blank")
+		}
+	}
+	for syn, v := range synonyms {
+		if strings.TrimSpace(syn) == "" {
+			return nil, errors.New("enum synonyms can't be blank")
+		}
+		if _, ok := e.value(v); !ok {
+			return nil, fmt.Errorf("synonym %q of %q: %q isn't any of %v", syn, v, v, values)
+		}
+		e.synonyms[strings.ToLower(syn)] = v
+	}
+	return e, nil
+}
+
+// equal returns true if e has the given values and synonyms
+func (e *enum) equal(values []string, synonyms map[string]string) bool {
+	if len(e.values) != len(values) || len(e.synonyms) != len(synonyms) {
+		return false
+	}
+	for i, v := range values {
+		if e.values[i] != v {
+			return false
+		}
+	}
+	for syn, v := range synonyms {
+		if e.synonyms[syn] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the allowed value s is or is a synonym of
+func (e *enum) value(s string) (string, bool) {
+	for _, v := range e.values {
+		if strings.EqualFold(s, v) {
+			return v, true
+		}
+	}
+	v, ok := e.synonyms[strings.ToLower(s)]
+	return v, ok
+}
+
+// resolve returns the allowed value of s, if s isn't allowed the
+// longest allowed value or synonym among the words of s is used
+func (e *enum) resolve(s string) (string, error) {
+	if v, ok := e.value(s); ok {
+		return v, nil
+	}
+	words := strings.Fields(s)
+	var best string
+	var bestLen int
+	try := func(c string) {
+		cw := strings.Fields(c)
+		if len(cw) <= bestLen {
+			return
+		}
+		for i := 0; i+len(cw) <= len(words); i++ {
+			if strings.EqualFold(strings.Join(words[i:i+len(cw)], " "), strings.Join(cw, " ")) {
+				best, bestLen = c, len(cw)
+				return
+			}
+		}
+	}
+	for _, v := range e.values {
+		try(v)
+	}
+	syns := make([]string, 0, len(e.synonyms))
+	for syn := range e.synonyms {
+		syns = append(syns, syn)
+	}
+	sort.Strings(syns)
+	for _, syn := range syns {
+		try(syn)
+	}
+	if bestLen == 0 {
+		return "", fmt.Errorf("%w: %q isn't any of %v", ErrNotAllowed, s, e.values)
+	}
+	v, _ := e.value(best)
+	return v, nil
+}
@@ -0,0 +1,266 @@
+package nlp
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+type field struct {
+	// index is the index sequence of the field inside the model,
+	// like reflect.StructField.Index for nested fields
+	index []int
+	// name is the keyword of the field
+	name    string
+	aliases []string
+	typ     reflect.Type
+	kind    interface{}
+	// ptr is true if typ is a pointer, it's
+	// only allocated when the field is set
+	ptr bool
+	// slice is true if typ is a slice of kind,
+	// or a pointer to it if ptr is true
+	slice bool
+	// layouts override the model time formats
+	layouts  []string
+	required bool
+	def      *string
+	// boolWords overrides the model bool words
+	boolWords *boolWords
+	enum      *enum
+	// pattern and as are set by the hints of the samples, pattern
+	// is the regexp the values must match and as is the type the
+	// values of string fields must be convertible to
+	pattern *regexp.Regexp
+	as      *field
+}
+
+// is returns true if kw is the keyword or an alias of f
+func (f field) is(kw []byte) bool {
+	if string(kw) == f.name {
+		return true
+	}
+	for _, a := range f.aliases {
+		if string(kw) == a {
+			return true
+		}
+	}
+	return false
+}
+
+// promoted is a field found by addFields with
+// the number of embedded structs it's inside of
+type promoted struct {
+	field
+	depth int
+}
+
+// addFields adds the supported fields of the model type to m.fields.
+// The fields of embedded structs are promoted like Go does, the
+// fields of nested structs are prefixed by the name of the struct:
+//
+//	{Guest.Name}
+func (m *model) addFields() error {
+	fields, err := m.collectFields(m.tpy, nil, "", 0, make(map[reflect.Type]bool))
+	if err != nil {
+		return err
+	}
+	// the shallowest field hides the others with the same keyword
+	depths := make(map[string]int)
+	for _, f := range fields {
+		if d, ok := depths[f.name]; !ok || f.depth < d {
+			depths[f.name] = f.depth
+		}
+	}
+	keywords := make(map[string]bool)
+	for _, f := range fields {
+		if f.depth > depths[f.name] {
+			continue
+		}
+		for _, kw := range append([]string{f.name}, f.aliases...) {
+			if keywords[kw] {
+				return fmt.Errorf("field %s: keyword %q is already in use", f.name, kw)
+			}
+			keywords[kw] = true
+		}
+		m.fields = append(m.fields, f.field)
+	}
+	for kw := range m.enums {
+		if !keywords[kw] {
+			return fmt.Errorf("enum of unknown field %q", kw)
+		}
+	}
+	return nil
+}
+
+// collectFields returns the supported fields of the struct tpy,
+// index is the index sequence of tpy inside the model, prefix is
+// prepended to the keywords and parents contains the structs
+// tpy is inside of, so recursive types aren't walked forever
+func (m *model) collectFields(tpy reflect.Type, index []int, prefix string, depth int, parents map[reflect.Type]bool) ([]promoted, error) {
+	if parents[tpy] {
+		return nil, nil
+	}
+	parents[tpy] = true
+	defer delete(parents, tpy)
+	var fields []promoted
+	for i := 0; i < tpy.NumField(); i++ {
+		sf := tpy.Field(i)
+		tag := sf.Tag.Get("nlp")
+		if tag == "-" {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		st := sf.Type
+		if st.Kind() == reflect.Ptr {
+			st = st.Elem()
+		}
+		// structs with their own conversion are values, not nested models
+		isStruct := st.Kind() == reflect.Struct && m.kindOf(st) == nil && m.kindOf(sf.Type) == nil
+		if sf.Anonymous && isStruct {
+			// unexported embedded pointers can't be allocated
+			if sf.PkgPath != "" && sf.Type.Kind() == reflect.Ptr {
+				continue
+			}
+			p, d := prefix, depth+1
+			if name := splitTag(tag)[0]; name != "" {
+				p, d = prefix+name+".", depth
+			}
+			promoted, err := m.collectFields(st, idx, p, d, parents)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := field{index: idx, name: sf.Name, typ: sf.Type}
+		t := sf.Type
+		if t.Kind() == reflect.Ptr && !isStruct && m.converters[t] == nil {
+			t, f.ptr = t.Elem(), true
+		}
+		f.kind = m.kindOf(t)
+		if f.kind == nil && t.Kind() == reflect.Slice {
+			f.kind, f.slice = m.kindOf(t.Elem()), true
+		}
+		if f.kind == nil {
+			if isStruct {
+				opts := splitTag(tag)
+				if len(opts) > 1 {
+					return nil, fmt.Errorf("field %s: only the keyword can be set in struct fields", prefix+sf.Name)
+				}
+				name := sf.Name
+				if opts[0] != "" {
+					name = opts[0]
+				}
+				nested, err := m.collectFields(st, idx, prefix+name+".", depth, parents)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+				continue
+			}
+			if tag != "" {
+				return nil, fmt.Errorf("field %s: unsupported type %v", prefix+sf.Name, sf.Type)
+			}
+			continue
+		}
+		err := f.setTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		f.name = prefix + f.name
+		for i := range f.aliases {
+			f.aliases[i] = prefix + f.aliases[i]
+		}
+		if _, ok := f.kind.(time.Time); !ok && len(f.layouts) > 0 {
+			return nil, fmt.Errorf("field %s: layout can only be set in time.Time fields", f.name)
+		}
+		if f.boolWords != nil {
+			if f.kind != reflect.Bool {
+				return nil, fmt.Errorf("field %s: bool words can only be set in bool fields", f.name)
+			}
+			if len(f.boolWords.truthy) == 0 {
+				f.boolWords.truthy = m.boolWords.truthy
+			}
+			if len(f.boolWords.falsy) == 0 {
+				f.boolWords.falsy = m.boolWords.falsy
+			}
+			err := f.boolWords.validate()
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %v", f.name, err)
+			}
+		}
+		if e, ok := m.enums[f.name]; ok {
+			if f.enum != nil {
+				return nil, fmt.Errorf("field %s: enum is set by both the tag and WithEnum", f.name)
+			}
+			f.enum = e
+		}
+		if f.enum != nil {
+			et := t
+			if f.slice {
+				et = t.Elem()
+			}
+			for _, v := range f.enum.values {
+				err := m.setValue(reflect.New(et).Elem(), f, v)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: invalid enum value %q: %v", f.name, v, err)
+				}
+			}
+		}
+		if f.def != nil {
+			err := m.set(reflect.New(m.tpy).Elem(), f, *f.def)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid default value %q: %v", f.name, *f.def, err)
+			}
+		}
+		fields = append(fields, promoted{f, depth})
+	}
+	return fields, nil
+}
+
+// kindOf returns the kind of value a field of type t holds, or nil
+// if t isn't supported. Kinds are either a reflect.Kind, a value
+// of the types with special parsing like time.Time and time.Duration
+// or a reflect.Type for the types converted by a converter of the
+// model or by encoding.TextUnmarshaler
+func (m *model) kindOf(t reflect.Type) interface{} {
+	if _, ok := m.converters[t]; ok {
+		return t
+	}
+	switch t {
+	case timeType:
+		return time.Time{}
+	case reflect.TypeOf(time.Duration(0)):
+		return time.Duration(0)
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return t
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String, reflect.Bool:
+		return t.Kind()
+	}
+	return nil
+}
+
+// fieldByIndex returns the nested field of v with the given index,
+// the nil pointers to structs along the way are allocated
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
@@ -0,0 +1,10 @@
+module github.com/shixzie/nlp
+
+go 1.20
+
+require (
+	github.com/cdipaolo/goml v0.0.0-20220715001353-00e0c845ae1c
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/text v0.3.6 // indirect
@@ -0,0 +1,82 @@
+package nlp
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// typeHints are the types named by the hints of the samples,
+// like int in {Count:int}, string fields hinted with them
+// only accept the values that can be converted to the type
+var typeHints = map[string]reflect.Type{
+	"int":      reflect.TypeOf(int64(0)),
+	"uint":     reflect.TypeOf(uint64(0)),
+	"float":    reflect.TypeOf(float64(0)),
+	"bool":     reflect.TypeOf(false),
+	"string":   reflect.TypeOf(""),
+	"time":     timeType,
+	"duration": reflect.TypeOf(time.Duration(0)),
+}
+
+// hint returns f with the hint of a sample keyword applied:
+//
+//	{Count:int}          the type of the field
+//	{Zip:/[0-9]{5}/}     a regexp the value must match, only the match is kept
+//	{ReleasedAt:2006}    the layout of a time.Time field
+func (m *model) hint(f field, hint string) (field, error) {
+	if hint == "" {
+		return f, nil
+	}
+	if len(hint) > 1 && strings.HasPrefix(hint, "/") && strings.HasSuffix(hint, "/") {
+		re, err := regexp.Compile(strings.ReplaceAll(hint[1:len(hint)-1], `\/`, "/"))
+		if err != nil {
+			return f, fmt.Errorf("invalid hint %q of %s: %v", hint, f.name, err)
+		}
+		f.pattern = re
+		return f, nil
+	}
+	if t, ok := typeHints[hint]; ok {
+		switch kindName(f.kind) {
+		case hint:
+		case "string":
+			as := field{name: f.name, typ: t, kind: m.kindOf(t)}
+			f.as = &as
+		default:
+			return f, fmt.Errorf("hint %q doesn't match the type %v of %s", hint, f.typ, f.name)
+		}
+		return f, nil
+	}
+	if _, ok := f.kind.(time.Time); !ok {
+		return f, fmt.Errorf("hint %q of %s isn't a type or a /regexp/, layouts are only allowed in time.Time fields", hint, f.name)
+	}
+	f.layouts = []string{hint}
+	return f, nil
+}
+
+// kindName returns the name of the type hint of kind, or an
+// empty string if there isn't any, like in converter fields
+func kindName(kind interface{}) string {
+	switch k := kind.(type) {
+	case time.Time:
+		return "time"
+	case time.Duration:
+		return "duration"
+	case reflect.Kind:
+		switch k {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return "int"
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return "uint"
+		case reflect.Float32, reflect.Float64:
+			return "float"
+		case reflect.Bool:
+			return "bool"
+		case reflect.String:
+			return "string"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,395 @@
+package nlp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadModels registers the models declared in the files of fsys matching
+// pattern, the type of every model is looked up by name in the registry.
+// Files ending with .json are read as JSON, files ending with .yaml or .yml
+// are read as YAML, only keys with a string or a list of strings are supported:
+//
+//	model: Song
+//	time_format: "2006"
+//	time_location: UTC
+//	time_formats: ["2006", Jan 2006]
+//	separators:
+//	  - ","
+//	  - and
+//	true_words: [yes, "on"]
+//	false_words: [no, "off"]
+//	samples:
+//	  - play {Name} by {Artist}
+//	  - play {Name}
+//
+// The samples starting with a keyword must be quoted, otherwise YAML
+// reads them as mappings.
+// The options in the files are applied after the ones in the registry.
+// If a file can't be loaded none of the models are registered
+func (nl *NL) LoadModels(fsys fs.FS, pattern string, registry *Registry) error {
+	if registry == nil {
+		return errors.New("registry can't be nil")
+	}
+	names, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no model files match %q", pattern)
+	}
+	var mods []*model
+	for _, name := range names {
+		mod, err := loadModelFile(fsys, name, registry)
+		if err != nil {
+			return err
+		}
+		mods = append(mods, mod)
+	}
+	nl.mu.Lock()
+	nl.models = append(nl.models, mods...)
+	nl.mu.Unlock()
+	return nil
+}
+
+// fileOptions contains the ModelOption that can be set
+// in a model file, indexed by their key
+var fileOptions = map[string]func(v string) (ModelOption, error){
+	"time_format": func(v string) (ModelOption, error) {
+		return WithTimeFormat(v), nil
+	},
+	"time_location": func(v string) (ModelOption, error) {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return nil, err
+		}
+		return WithTimeLocation(loc), nil
+	},
+}
+
+// fileListOptions contains the ModelOption that can be set as
+// a list of strings in a model file, indexed by their key
+var fileListOptions = map[string]func(vs []string) (ModelOption, error){
+	"time_formats": func(vs []string) (ModelOption, error) {
+		return WithTimeFormats(vs...), nil
+	},
+	"separators": func(vs []string) (ModelOption, error) {
+		return WithSeparators(vs...), nil
+	},
+	"true_words": func(vs []string) (ModelOption, error) {
+		return func(m *model) error {
+			return WithBoolWords(vs, m.boolWords.falsy)(m)
+		}, nil
+	},
+	"false_words": func(vs []string) (ModelOption, error) {
+		return func(m *model) error {
+			return WithBoolWords(m.boolWords.truthy, vs)(m)
+		}, nil
+	},
+}
+
+// loadModelFile creates the model declared in the file name
+func loadModelFile(fsys fs.FS, name string, registry *Registry) (*model, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	var mf *modelFile
+	switch path.Ext(name) {
+	case ".json":
+		mf, err = parseJSONModelFile(data)
+	case ".yaml", ".yml":
+		mf, err = parseYAMLModelFile(data)
+	default:
+		return nil, fmt.Errorf("%s: unsupported model file format", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", name, err)
+	}
+	mod, err := mf.model(registry)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%v", name, err)
+	}
+	return mod, nil
+}
+
+// modelFile contains the keys of a model file
+type modelFile struct {
+	// keys in the order they appear in the file
+	keys   []string
+	values map[string]fileValue
+	lists  map[string][]fileValue
+}
+
+// fileValue is a value read from the line of a file
+type fileValue struct {
+	line int
+	val  string
+}
+
+func lineErrorf(line int, format string, a ...interface{}) error {
+	return fmt.Errorf("%d: %s", line, fmt.Sprintf(format, a...))
+}
+
+func (mf *modelFile) set(key string, line int, val string) error {
+	if mf.values == nil {
+		mf.values = make(map[string]fileValue)
+		mf.lists = make(map[string][]fileValue)
+	}
+	if _, ok := mf.values[key]; ok {
+		return lineErrorf(line, "duplicated key %q", key)
+	}
+	mf.keys = append(mf.keys, key)
+	mf.values[key] = fileValue{line, val}
+	return nil
+}
+
+func (mf *modelFile) setList(key string, line int) error {
+	err := mf.set(key, line, "")
+	if err != nil {
+		return err
+	}
+	mf.lists[key] = []fileValue{}
+	return nil
+}
+
+// model creates the model declared in mf
+func (mf *modelFile) model(registry *Registry) (*model, error) {
+	tpy, ok := mf.values["model"]
+	if !ok {
+		return nil, lineErrorf(1, "missing key \"model\"")
+	}
+	if _, ok := mf.lists["model"]; ok {
+		return nil, lineErrorf(tpy.line, "\"model\" must be a string")
+	}
+	entry, ok := registry.types[tpy.val]
+	if !ok {
+		return nil, lineErrorf(tpy.line, "type %s isn't in the registry", tpy.val)
+	}
+	samples, ok := mf.lists["samples"]
+	if v, isValue := mf.values["samples"]; isValue && !ok {
+		return nil, lineErrorf(v.line, "\"samples\" must be a list of strings")
+	}
+	if !ok {
+		return nil, lineErrorf(tpy.line, "missing list \"samples\"")
+	}
+	ops := append([]ModelOption{}, entry.ops...)
+	for _, key := range mf.keys {
+		if key == "model" || key == "samples" {
+			continue
+		}
+		v := mf.values[key]
+		list, isList := mf.lists[key]
+		var op ModelOption
+		var err error
+		if newOption, ok := fileOptions[key]; ok {
+			if isList {
+				return nil, lineErrorf(v.line, "%q must be a string", key)
+			}
+			op, err = newOption(v.val)
+		} else if newOption, ok := fileListOptions[key]; ok {
+			if !isList {
+				return nil, lineErrorf(v.line, "%q must be a list of strings", key)
+			}
+			vals := make([]string, len(list))
+			for i, l := range list {
+				vals[i] = l.val
+			}
+			op, err = newOption(vals)
+		} else {
+			return nil, lineErrorf(v.line, "unknown key %q", key)
+		}
+		if err != nil {
+			return nil, lineErrorf(v.line, "%v", err)
+		}
+		ops = append(ops, func(m *model) error {
+			err := op(m)
+			if err != nil {
+				return lineErrorf(v.line, "%v", err)
+			}
+			return nil
+		})
+	}
+	strs := make([]string, len(samples))
+	for i, s := range samples {
+		strs[i] = s.val
+	}
+	mod, err := newModel(entry.i, strs, ops...)
+	if err != nil {
+		if len(samples) == 0 {
+			return nil, lineErrorf(mf.values["samples"].line, "%v", err)
+		}
+		return nil, err
+	}
+	// the samples are learned ahead of time to report errors with their line
+	learner := mod.clone()
+	for sid, s := range samples {
+		err := learner.learnSample(sid)
+		if err != nil {
+			return nil, lineErrorf(s.line, "%v", err)
+		}
+	}
+	return mod, nil
+}
+
+// parseYAMLModelFile reads a model file written in YAML, only
+// keys with a string value or a list of strings are supported
+func parseYAMLModelFile(data []byte) (*modelFile, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, yamlError(err)
+	}
+	mf := &modelFile{}
+	if len(doc.Content) == 0 {
+		// empty file
+		return mf, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, lineErrorf(root.Line, "expected a mapping of keys")
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, val := root.Content[i], root.Content[i+1]
+		if val.Kind == yaml.AliasNode {
+			val = val.Alias
+		}
+		switch {
+		case val.Kind == yaml.ScalarNode && val.Tag == "!!null":
+			// samples: with its items missing
+			if err := mf.setList(key.Value, key.Line); err != nil {
+				return nil, err
+			}
+		case val.Kind == yaml.ScalarNode:
+			if err := mf.set(key.Value, key.Line, val.Value); err != nil {
+				return nil, err
+			}
+		case val.Kind == yaml.SequenceNode:
+			if err := mf.setList(key.Value, key.Line); err != nil {
+				return nil, err
+			}
+			for _, item := range val.Content {
+				if item.Kind == yaml.AliasNode {
+					item = item.Alias
+				}
+				if item.Kind != yaml.ScalarNode {
+					return nil, lineErrorf(item.Line, "%q must be a list of strings, quote the items starting with { or [", key.Value)
+				}
+				mf.lists[key.Value] = append(mf.lists[key.Value], fileValue{item.Line, item.Value})
+			}
+		default:
+			return nil, lineErrorf(key.Line, "%q must be a string or a list of strings", key.Value)
+		}
+	}
+	return mf, nil
+}
+
+// yamlParserErrors are the errors of the YAML parser, unlike the
+// errors of its scanner they're reported with 0-based lines
+var yamlParserErrors = map[string]bool{
+	"did not find expected ',' or ']'":       true,
+	"did not find expected ',' or '}'":       true,
+	"did not find expected '-' indicator":    true,
+	"did not find expected <document start>": true,
+	"did not find expected <stream-start>":   true,
+	"did not find expected key":              true,
+	"did not find expected node content":     true,
+	"found duplicate %TAG directive":         true,
+	"found duplicate %YAML directive":        true,
+	"found incompatible YAML document":       true,
+	"found undefined tag handle":             true,
+}
+
+// yamlError returns err, like "yaml: line 3: did not find expected key",
+// in the format of lineErrorf
+func yamlError(err error) error {
+	msg := strings.TrimPrefix(err.Error(), "yaml: ")
+	if rest, ok := strings.CutPrefix(msg, "line "); ok {
+		if n, problem, ok := strings.Cut(rest, ": "); ok {
+			if line, err := strconv.Atoi(n); err == nil {
+				if yamlParserErrors[problem] {
+					line++
+				}
+				return lineErrorf(line, "%s", problem)
+			}
+		}
+	}
+	return lineErrorf(1, "%s", msg)
+}
+
+// parseJSONModelFile reads a model file written in JSON, only keys
+// with a string value or a list of strings are supported
+func parseJSONModelFile(data []byte) (*modelFile, error) {
+	mf := &modelFile{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	line := func(offset int64) int {
+		return 1 + bytes.Count(data[:offset], []byte{'\n'})
+	}
+	token := func() (json.Token, error) {
+		tk, err := dec.Token()
+		if err != nil {
+			if se, ok := err.(*json.SyntaxError); ok {
+				return nil, lineErrorf(line(se.Offset), "%v", err)
+			}
+			return nil, lineErrorf(line(dec.InputOffset()), "%v", err)
+		}
+		return tk, nil
+	}
+	tk, err := token()
+	if err != nil {
+		return nil, err
+	}
+	if tk != json.Delim('{') {
+		return nil, lineErrorf(line(dec.InputOffset()), "expected an object")
+	}
+	for dec.More() {
+		tk, err := token()
+		if err != nil {
+			return nil, err
+		}
+		key := tk.(string)
+		tk, err = token()
+		if err != nil {
+			return nil, err
+		}
+		n := line(dec.InputOffset())
+		switch v := tk.(type) {
+		case string:
+			if err := mf.set(key, n, v); err != nil {
+				return nil, err
+			}
+		case json.Delim:
+			if v != '[' {
+				return nil, lineErrorf(n, "%q must be a string or a list of strings", key)
+			}
+			if err := mf.setList(key, n); err != nil {
+				return nil, err
+			}
+			for dec.More() {
+				tk, err := token()
+				if err != nil {
+					return nil, err
+				}
+				s, ok := tk.(string)
+				if !ok {
+					return nil, lineErrorf(line(dec.InputOffset()), "%q must be a list of strings", key)
+				}
+				mf.lists[key] = append(mf.lists[key], fileValue{line(dec.InputOffset()), s})
+			}
+			if _, err := token(); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, lineErrorf(n, "%q must be a string or a list of strings", key)
+		}
+	}
+	return mf, nil
+}
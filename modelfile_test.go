@@ -0,0 +1,132 @@
+package nlp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type persistPizza struct {
+	Toppings []string
+}
+
+func TestNL_LoadModels(t *testing.T) {
+	fsys := fstest.MapFS{
+		"intents/song.yaml": {Data: []byte(`# songs
+model: persistSong
+time_format: "2006"
+time_location: UTC
+samples:
+  - play {Name}
+    by {Artist}
+  - 'play {Name}'
+  - play something from {ReleasedAt} # comment
+`)},
+		"intents/timer.json": {Data: []byte(`{
+	"model": "persistTimer",
+	"samples": [
+		"set a timer for {Dur}",
+		"timer {Dur}"
+	]
+}`)},
+		"broken/mistyped.yaml": {Data: []byte(`model: persistSong
+samples:
+  - play {Name}
+  - play {Nme}
+`)},
+		"broken/mistyped.json": {Data: []byte(`{
+	"model": "persistSong",
+	"samples": [
+		"play {Name}",
+		"play {Nme}"
+	]
+}`)},
+		"broken/syntax.json": {Data: []byte(`{
+	"model": "persistSong",
+	"samples": [
+		"play {Name}",
+	]
+}`)},
+		"intents/pizza.yaml": {Data: []byte(`model: persistPizza
+separators: [";", 'plus'] # inline list
+samples: ["pizza with {Toppings}",
+  'pizza {Toppings}']
+`)},
+		"broken/separators.yaml":  {Data: []byte("model: persistPizza\nseparators: \",\"\nsamples:\n  - pizza with {Toppings}\n")},
+		"broken/unknown.yaml":     {Data: []byte("model: persistSong\nsamples:\n  - play {Name}\ncolor: red\n")},
+		"broken/unregistered.yml": {Data: []byte("model: Album\nsamples:\n  - play {Name}\n")},
+		"broken/format.yaml":      {Data: []byte("model: persistSong\ntime_format: \" \"\nsamples:\n  - play {Name}\n")},
+		"broken/indent.yaml":      {Data: []byte("model: persistSong\n  samples:\n")},
+		"broken/nosamples.yaml":   {Data: []byte("model: persistSong\nsamples:\n")},
+		"broken/sample.yaml":      {Data: []byte("model: persistSong\nsamples:\n  - play {Name\n")},
+		"broken/ext.txt":          {Data: []byte("model: persistSong\n")},
+		"broken/inline.yaml":      {Data: []byte("model: persistSong\nsamples: [play {Name}]\n")},
+		"broken/mapping.yaml":     {Data: []byte("model: persistSong\nsamples:\n  - {Name}\n")},
+		"broken/empty.yaml":       {Data: []byte("model: persistPizza\nseparators: [\";\", , and]\nsamples: [\"pizza with {Toppings}\"]\n")},
+		"broken/scalar.yaml":      {Data: []byte("model: persistSong\nsamples: play {Name}\n")},
+	}
+	registry := NewRegistry()
+	failTest(t, registry.Add(persistSong{}))
+	failTest(t, registry.Add(persistTimer{}))
+	failTest(t, registry.Add(persistPizza{}))
+
+	tests := []struct {
+		name    string
+		pattern string
+		wantErr string
+	}{
+		0:  {"no match", "none/*", `no model files match "none/*"`},
+		1:  {"mistyped yaml", "broken/mistyped.yaml", "broken/mistyped.yaml:4: sample#1: mistyped field \"Nme\""},
+		2:  {"mistyped json", "broken/mistyped.json", "broken/mistyped.json:5: sample#1: mistyped field \"Nme\""},
+		3:  {"json syntax", "broken/syntax.json", "broken/syntax.json:4: "},
+		4:  {"unknown key", "broken/unknown.yaml", "broken/unknown.yaml:4: unknown key \"color\""},
+		5:  {"unregistered", "broken/unregistered.yml", "broken/unregistered.yml:1: type Album isn't in the registry"},
+		6:  {"option", "broken/format.yaml", "broken/format.yaml:2: time formats can't be blank"},
+		7:  {"indentation", "broken/indent.yaml", "broken/indent.yaml:2: mapping values are not allowed in this context"},
+		8:  {"no samples", "broken/nosamples.yaml", "broken/nosamples.yaml:2: samples can't be nil or empty"},
+		9:  {"sample syntax", "broken/sample.yaml", "broken/sample.yaml:3: sample#0: "},
+		10: {"extension", "broken/ext.txt", "broken/ext.txt: unsupported model file format"},
+		11: {"list option", "broken/separators.yaml", "broken/separators.yaml:2: \"separators\" must be a list of strings"},
+		12: {"unquoted inline item", "broken/inline.yaml", "broken/inline.yaml:2: did not find expected ',' or ']'"},
+		13: {"mapping sample", "broken/mapping.yaml", "broken/mapping.yaml:3: \"samples\" must be a list of strings"},
+		14: {"empty inline item", "broken/empty.yaml", "broken/empty.yaml:2: did not find expected node content"},
+		15: {"scalar samples", "broken/scalar.yaml", "broken/scalar.yaml:2: \"samples\" must be a list of strings"},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := New()
+			err := nl.LoadModels(fsys, tt.pattern, registry)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("[%d] NL.LoadModels() error = %v, want %q", i, err, tt.wantErr)
+			}
+			if len(nl.models) != 0 {
+				t.Errorf("[%d] NL.LoadModels() registered %d models", i, len(nl.models))
+			}
+		})
+	}
+
+	nl := New()
+	failTest(t, nl.LoadModels(fsys, "intents/*", registry))
+	failTest(t, nl.Learn())
+
+	rel, err := time.ParseInLocation("2006", "1999", time.UTC)
+	failTest(t, err)
+
+	cases := []struct {
+		expression string
+		want       interface{}
+	}{
+		0: {"hello play King by Lauren Aquilina", &persistSong{Name: "King", Artist: "Lauren Aquilina"}},
+		1: {"anything from 1999", &persistSong{ReleasedAt: rel}},
+		2: {"set a timer for 4h2m", &persistTimer{Dur: 4*time.Hour + 2*time.Minute}},
+		3: {"pizza with ham, olives;mushrooms", &persistPizza{Toppings: []string{"ham, olives", "mushrooms"}}},
+		4: {"pizza ham plus olives", &persistPizza{Toppings: []string{"ham", "olives"}}},
+	}
+	for i, tt := range cases {
+		if res := nl.P(tt.expression); !reflect.DeepEqual(res, tt.want) {
+			t.Errorf("[%d] NL.P() = %v, want %v", i, res, tt.want)
+		}
+	}
+}
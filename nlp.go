@@ -5,8 +5,13 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unicode"
 
@@ -15,44 +20,283 @@ import (
 	"github.com/shixzie/nlp/parser"
 )
 
-// NL is a Natural Language Processor
+// NL is a Natural Language Processor, it's safe
+// for concurrent use by multiple goroutines
 type NL struct {
+	// mu guards models and serializes calls to Learn
+	mu     sync.Mutex
 	models []*model
-	naive  *text.NaiveBayes
+	// state is replaced by every call to Learn,
+	// so it's never modified while processing an expression
+	state atomic.Pointer[state]
 	// Output contains the training output for the
 	// NaiveBayes algorithm
 	Output *bytes.Buffer
+
+	minProbability float64
+	minScore       int
+	noMatch        [][]byte
+}
+
+// state contains the models and the NaiveBayes algorithm
+// trained by a call to Learn
+type state struct {
+	models []*model
+	naive  *text.NaiveBayes
 }
 
+// ErrNoMatch is returned when an expression doesn't
+// belong to any of the registered models
+var ErrNoMatch = errors.New("expression doesn't match any model")
+
 // New returns a *NL
-func New() *NL { return &NL{Output: bytes.NewBufferString("")} }
+func New(ops ...Option) *NL {
+	nl := &NL{Output: bytes.NewBufferString("")}
+	for _, op := range ops {
+		op(nl)
+	}
+	return nl
+}
+
+// Option is an option for a NL
+type Option func(*NL)
+
+// WithMinProbability sets the minimum probability, between 0 and 1,
+// the NaiveBayes algorithm must give to a model for an expression
+// to be processed by it, the default is 0
+func WithMinProbability(p float64) Option {
+	return func(nl *NL) { nl.minProbability = p }
+}
+
+// WithMinScore sets the minimum score an expression must reach
+// with the best sample of a model to be processed by it, the
+// score increases by 1 for every limit of the sample found in the
+// expression and by 1 if they're in the same order, the default is 0
+func WithMinScore(score int) Option {
+	return func(nl *NL) { nl.minScore = score }
+}
+
+// WithNoMatchSamples trains an extra class with samples
+// of expressions that don't belong to any model, the
+// expressions classified as such don't match any model.
+// Unlike model samples these can't contain any keyword
+func WithNoMatchSamples(samples ...string) Option {
+	return func(nl *NL) {
+		for _, s := range samples {
+			nl.noMatch = append(nl.noMatch, []byte(s))
+		}
+	}
+}
 
 // P proccesses the expr and returns one of
 // the types passed as the i parameter to the RegistryModel
-// func filled with the data inside expr
-func (nl *NL) P(expr string) interface{} { return nl.models[nl.naive.Predict(expr)].fit(expr) }
+// func filled with the data inside expr, the values that
+// can't be converted to the type of their fields are left empty.
+// If expr doesn't match any model nil is returned
+func (nl *NL) P(expr string) interface{} {
+	r, _ := nl.Parse(expr)
+	if r == nil {
+		return nil
+	}
+	return r.Value
+}
+
+// Result is the outcome of processing an expression with NL.Parse
+type Result struct {
+	// Value is a pointer to a filled struct of the type
+	// passed to RegisterModel
+	Value interface{}
+	// Model is the index of the model that handled the expression,
+	// models are indexed in the order they were registered
+	Model int
+	// Type is the type passed to RegisterModel
+	Type reflect.Type
+	// Sample is the sample that fits the expression best
+	Sample string
+	// Optionals tells which optional groups of Sample, like
+	// [by {Artist}], are present in the expression, in order
+	Optionals []bool
+	// Probability is the probability given by the
+	// NaiveBayes algorithm to the chosen model
+	Probability float64
+	// Score is the score the expression reached with Sample
+	Score int
+	// Captures contains the raw values read from the expression
+	Captures []Capture
+}
+
+// Capture is a raw value read from an expression for a keyword
+type Capture struct {
+	// Field is the name of the keyword
+	Field string
+	// Value is the text read from the expression
+	Value string
+	// Start and End delimit the tokens [Start, End) of
+	// the expression Value was read from
+	Start, End int
+	// Layout is the layout that parsed the value of a time.Time
+	// field, the one of the first element in slice fields
+	Layout string
+}
+
+// Parse proccesses the expr like P does, but it also returns
+// information about how the expression was processed.
+// If some values can't be converted to the type of their
+// fields, the *Result is returned along with a FieldErrors.
+// If expr doesn't match any model ErrNoMatch is returned
+func (nl *NL) Parse(expr string) (*Result, error) {
+	st := nl.state.Load()
+	if st == nil {
+		return nil, errors.New("call Learn before processing expressions")
+	}
+	probs := st.probabilities(expr)
+	var best int
+	for id, p := range probs {
+		if p > probs[best] {
+			best = id
+		}
+	}
+	if best == len(st.models) || probs[best] < nl.minProbability {
+		return nil, ErrNoMatch
+	}
+	r, err := st.fit(best, probs[best], expr)
+	if r.Score < nl.minScore {
+		return nil, ErrNoMatch
+	}
+	return r, err
+}
+
+// PTopN proccesses the expr with the n models the expr most likely
+// belongs to and returns their results sorted by probability,
+// the results are the same that Parse would return for each model
+// without taking into account WithMinProbability and WithMinScore
+func (nl *NL) PTopN(expr string, n int) []*Result {
+	st := nl.state.Load()
+	if st == nil || n <= 0 {
+		return nil
+	}
+	// the probability of WithNoMatchSamples is left out
+	probs := st.probabilities(expr)[:len(st.models)]
+	ids := make([]int, len(probs))
+	for id := range ids {
+		ids[id] = id
+	}
+	sort.SliceStable(ids, func(i, j int) bool { return probs[ids[i]] > probs[ids[j]] })
+	if n > len(ids) {
+		n = len(ids)
+	}
+	results := make([]*Result, n)
+	for i, id := range ids[:n] {
+		results[i], _ = st.fit(id, probs[id], expr)
+	}
+	return results
+}
+
+// fit makes the model id fit the expr
+func (st *state) fit(id int, prob float64, expr string) (*Result, error) {
+	m := st.models[id]
+	val, mt, err := m.fit(expr)
+	r := &Result{
+		Value:       val,
+		Model:       id,
+		Type:        m.tpy,
+		Probability: prob,
+	}
+	if mt != nil {
+		r.Sample = string(m.samples[mt.sample])
+		r.Optionals = mt.optionals
+		r.Score = mt.score
+		for _, c := range mt.captures {
+			r.Captures = append(r.Captures, Capture{
+				Field:  c.field.name,
+				Value:  string(c.value),
+				Start:  c.start,
+				End:    c.end,
+				Layout: c.layout,
+			})
+		}
+	}
+	return r, err
+}
+
+// probabilities returns the probability given by the NaiveBayes
+// algorithm to every model, it follows text.NaiveBayes.Probability
+// but keeps the probability of every model and not just the best one
+func (st *state) probabilities(expr string) []float64 {
+	sums := make([]float64, len(st.naive.Count))
+	expr = strings.Map(func(r rune) rune {
+		if base.OnlyWordsAndNumbers(r) {
+			return -1
+		}
+		return r
+	}, expr)
+	for _, word := range strings.Split(strings.ToLower(expr), " ") {
+		w, ok := st.naive.Words.Get(word)
+		if !ok {
+			continue
+		}
+		for i := range sums {
+			sums[i] += math.Log(float64(w.Count[i]+1) / float64(w.Seen+st.naive.DictCount))
+		}
+	}
+	// sums are kept as logarithms so long expressions don't underflow
+	max := math.Inf(-1)
+	for i := range sums {
+		sums[i] += math.Log(st.naive.Probabilities[i])
+		if sums[i] > max {
+			max = sums[i]
+		}
+	}
+	var denom float64
+	for i := range sums {
+		sums[i] = math.Exp(sums[i] - max)
+		denom += sums[i]
+	}
+	for i := range sums {
+		sums[i] /= denom
+	}
+	return sums
+}
 
 // Learn maps the models samples to the models themselves and
-// returns an error if something occurred while learning
+// returns an error if something occurred while learning.
+// The models are trained apart from the ones in use, so Learn
+// can be called while other goroutines are processing expressions
 func (nl *NL) Learn() error {
+	nl.mu.Lock()
+	defer nl.mu.Unlock()
 	if len(nl.models) > 0 {
-		stream := make(chan base.TextDatapoint)
-		errors := make(chan error)
-		nl.naive = text.NewNaiveBayes(stream, uint8(len(nl.models)), base.OnlyWordsAndNumbers)
-		nl.naive.Output = nl.Output
-		go nl.naive.OnlineLearn(errors)
-		for i := range nl.models {
-			err := nl.models[i].learn()
+		st := &state{models: make([]*model, len(nl.models))}
+		for i, m := range nl.models {
+			st.models[i] = m.clone()
+			err := st.models[i].learn()
 			if err != nil {
 				return fmt.Errorf("model#%d %v", i, err)
 			}
-			for _, s := range nl.models[i].samples {
+		}
+		stream := make(chan base.TextDatapoint)
+		errors := make(chan error)
+		classes := len(st.models)
+		if len(nl.noMatch) > 0 {
+			classes++
+		}
+		st.naive = text.NewNaiveBayes(stream, uint8(classes), base.OnlyWordsAndNumbers)
+		st.naive.Output = nl.Output
+		go st.naive.OnlineLearn(errors)
+		for i, m := range st.models {
+			for _, s := range m.texts {
 				stream <- base.TextDatapoint{
-					X: string(s),
+					X: s,
 					Y: uint8(i),
 				}
 			}
 		}
+		for _, s := range nl.noMatch {
+			stream <- base.TextDatapoint{
+				X: string(s),
+				Y: uint8(len(st.models)),
+			}
+		}
 		close(stream)
 		for {
 			err := <-errors
@@ -62,45 +306,68 @@ func (nl *NL) Learn() error {
 			// training is done!
 			break
 		}
+		nl.state.Store(st)
 		return nil
 	}
 	return fmt.Errorf("register at least one model before learning")
 }
 
 type model struct {
-	tpy          reflect.Type
-	fields       []field
-	expected     [][]item
-	samples      [][]byte
-	timeFormat   string
+	tpy      reflect.Type
+	fields   []field
+	expected [][]variant
+	samples  [][]byte
+	// texts are the texts of the samples learned by the classifier
+	texts        []string
+	timeFormats  []string
 	timeLocation *time.Location
+	separators   []string
+	boolWords    boolWords
+	now          func() time.Time
+	numbers      []NumberWords
+	converters   map[reflect.Type]Converter
+	// enums are the enums set by WithEnum indexed by keyword
+	enums map[string]*enum
+}
+
+// boolWords are the words accepted by bool fields
+type boolWords struct {
+	truthy, falsy []string
 }
 
 type item struct {
 	limit bool
 	value []byte
+	// alternatives are the alternatives of a limit like (play|put on),
+	// with their words separated by a space
+	alternatives [][]byte
+	// hint is the hint of the keyword, already applied to field
+	hint  string
 	field field
 }
 
-type field struct {
-	index int
-	name  string
-	kind  interface{}
-}
-
 // ModelOption is an option for a specific model
 type ModelOption func(*model) error
 
 // WithTimeFormat sets the format used in time.Parse(format, val),
-// note that format can't contain any spaces, the default is 01-02-2006_3:04pm
+// the default is 01-02-2006_3:04pm
 func WithTimeFormat(format string) ModelOption {
+	return WithTimeFormats(format)
+}
+
+// WithTimeFormats sets the formats used in time.Parse(format, val),
+// they're tried in order until one of them parses the value
+func WithTimeFormats(formats ...string) ModelOption {
 	return func(m *model) error {
-		for _, v := range format {
-			if unicode.IsSpace(v) {
-				return errors.New("time format can't contain any spaces")
+		if len(formats) == 0 {
+			return errors.New("time formats can't be empty")
+		}
+		for _, format := range formats {
+			if strings.TrimSpace(format) == "" {
+				return errors.New("time formats can't be blank")
 			}
 		}
-		m.timeFormat = format
+		m.timeFormats = formats
 		return nil
 	}
 }
@@ -117,6 +384,43 @@ func WithTimeLocation(loc *time.Location) ModelOption {
 	}
 }
 
+// WithSeparators sets the separators between the elements of slice fields,
+// separators made of letters only separate whole words, the default
+// separators are "," "and" "or":
+//
+//	"ham, olives and mushrooms" -> []string{"ham", "olives", "mushrooms"}
+func WithSeparators(seps ...string) ModelOption {
+	return func(m *model) error {
+		if len(seps) == 0 {
+			return errors.New("separators can't be empty")
+		}
+		for _, sep := range seps {
+			if strings.TrimSpace(sep) == "" {
+				return errors.New("separators can't be blank")
+			}
+		}
+		m.separators = seps
+		return nil
+	}
+}
+
+// WithBoolWords sets the words bool fields accept as true and as false,
+// words are case insensitive, the defaults are:
+//
+//	true:  yes, on, true, enable
+//	false: no, off, false, disable
+func WithBoolWords(truthy, falsy []string) ModelOption {
+	return func(m *model) error {
+		bw := boolWords{truthy, falsy}
+		err := bw.validate()
+		if err != nil {
+			return err
+		}
+		m.boolWords = bw
+		return nil
+	}
+}
+
 // RegisterModel registers a model i and creates possible patterns
 // from samples, the default layout when parsing time is 01-02-2006_3:04pm
 // and the default location is time.Local.
@@ -124,222 +428,843 @@ func WithTimeLocation(loc *time.Location) ModelOption {
 //
 //	"play {Name} by {Artist}"
 func (nl *NL) RegisterModel(i interface{}, samples []string, ops ...ModelOption) error {
+	mod, err := newModel(i, samples, ops...)
+	if err != nil {
+		return err
+	}
+	nl.mu.Lock()
+	nl.models = append(nl.models, mod)
+	nl.mu.Unlock()
+	return nil
+}
+
+// Model is a model registered with Register
+type Model[T any] struct {
+	nl *NL
+	id int
+}
+
+// Register registers T as a model just like RegisterModel does,
+// the returned *Model processes expressions into a *T
+//
+//	songs, err := nlp.Register[Song](nl, songSamples)
+func Register[T any](nl *NL, samples []string, ops ...ModelOption) (*Model[T], error) {
+	var i T
+	mod, err := newModel(i, samples, ops...)
+	if err != nil {
+		return nil, err
+	}
+	nl.mu.Lock()
+	defer nl.mu.Unlock()
+	nl.models = append(nl.models, mod)
+	return &Model[T]{nl: nl, id: len(nl.models) - 1}, nil
+}
+
+// Parse proccesses the expr like NL.P does, it returns
+// false if the expr doesn't belong to the model
+func (m *Model[T]) Parse(expr string) (*T, bool) {
+	r, _ := m.nl.Parse(expr)
+	if r == nil || r.Model != m.id {
+		return nil, false
+	}
+	return r.Value.(*T), true
+}
+
+// newModel creates a model from i and its samples
+func newModel(i interface{}, samples []string, ops ...ModelOption) (*model, error) {
 	if i == nil {
-		return fmt.Errorf("can't create model from nil value")
+		return nil, fmt.Errorf("can't create model from nil value")
 	}
 	if len(samples) == 0 {
-		return fmt.Errorf("samples can't be nil or empty")
+		return nil, fmt.Errorf("samples can't be nil or empty")
 	}
-	tpy, val := reflect.TypeOf(i), reflect.ValueOf(i)
+	tpy := reflect.TypeOf(i)
 	if tpy.Kind() == reflect.Struct {
 		mod := &model{
 			tpy:          tpy,
-			expected:     make([][]item, len(samples)),
-			timeFormat:   "01-02-2006_3:04pm",
+			expected:     make([][]variant, len(samples)),
+			timeFormats:  []string{"01-02-2006_3:04pm"},
 			timeLocation: time.Local,
+			now:          time.Now,
+			numbers:      []NumberWords{EnglishNumbers},
+			separators:   []string{",", "and", "or"},
+			boolWords: boolWords{
+				truthy: []string{"yes", "on", "true", "enable"},
+				falsy:  []string{"no", "off", "false", "disable"},
+			},
 		}
 		mod.setSamples(samples)
 		for _, op := range ops {
 			err := op(mod)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
-	NextField:
-		for i := 0; i < tpy.NumField(); i++ {
-			if tpy.Field(i).Anonymous || tpy.Field(i).PkgPath != "" {
-				continue NextField
-			}
-			if v, ok := val.Field(i).Interface().(time.Time); ok {
-				mod.fields = append(mod.fields, field{i, tpy.Field(i).Name, v})
-				continue NextField
-			} else if v, ok := val.Field(i).Interface().(time.Duration); ok {
-				mod.fields = append(mod.fields, field{i, tpy.Field(i).Name, v})
-				continue NextField
-			}
-			switch val.Field(i).Kind() {
-			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.String:
-				mod.fields = append(mod.fields, field{i, tpy.Field(i).Name, val.Field(i).Kind()})
-			}
+		err := mod.addFields()
+		if err != nil {
+			return nil, err
 		}
-		nl.models = append(nl.models, mod)
-		return nil
+		return mod, nil
 	}
-	return fmt.Errorf("can't create model from non-struct type")
+	return nil, fmt.Errorf("can't create model from non-struct type")
 }
 
 func (m *model) learn() error {
-	for sid, s := range m.samples {
-		tokens, err := parser.ParseSample(sid, s)
+	for sid := range m.samples {
+		err := m.learnSample(sid)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// maxOptionals is the maximum number of optional groups
+// in a sample, every combination of them is a variant
+const maxOptionals = 8
+
+// maxAdjacent is the maximum number of tokens taken by a keyword
+// followed by another keyword without a limit between them, like
+// Amount in {Amount} {Currency}, the last one takes the rest
+const maxAdjacent = 16
+
+// variant is one of the item sequences a sample expands to, one for
+// every combination of its optional groups, a sample without
+// optional groups has a single variant
+type variant struct {
+	// optionals tells which optional groups of the sample are present
+	optionals []bool
+	items     []item
+}
+
+// learnSample maps the sample sid to the expected items of its variants,
+// the variant with every optional group goes first so it wins the ties
+func (m *model) learnSample(sid int) error {
+	tokens, err := parser.ParseSample(sid, m.samples[sid])
+	if err != nil {
+		return err
+	}
+	var optionals int
+	for _, tk := range tokens {
+		if tk.Optional > optionals {
+			optionals = tk.Optional
+		}
+	}
+	m.texts[sid] = trainingText(tokens)
+	if optionals > maxOptionals {
+		return fmt.Errorf("sample#%d: too many optional groups, the maximum is %d", sid, maxOptionals)
+	}
+	var variants []variant
+	for set := 1<<optionals - 1; set >= 0; set-- {
+		var v variant
+		for i := 0; i < optionals; i++ {
+			v.optionals = append(v.optionals, set&(1<<i) != 0)
+		}
+		var present []parser.Token
+		for _, tk := range tokens {
+			if tk.Optional == 0 || v.optionals[tk.Optional-1] {
+				present = append(present, tk)
+			}
+		}
+		items, hasKey, err := m.learnTokens(sid, present)
 		if err != nil {
 			return err
 		}
-		var exps []item
-		var hasAtLeastOneKey bool
-		l := len(tokens)
-		for i, tk := range tokens {
-			if tk.Kw {
-				hasAtLeastOneKey = true
-				mistypedField := true
-				for _, f := range m.fields {
-					if string(tk.Val) == f.name {
-						mistypedField = false
-						exps = append(exps, item{field: f, value: tk.Val})
+		// [play {Name}] doesn't expand to an empty sample
+		if hasKey {
+			v.items = items
+			variants = append(variants, v)
+		}
+	}
+	if len(variants) == 0 {
+		return fmt.Errorf("sample#%d: need at least one keyword", sid)
+	}
+	m.expected[sid] = variants
+	return nil
+}
+
+// trainingText returns the text the classifier learns from the tokens of a
+// sample, the alternatives of an alternation are learned in the same
+// text, so a model with many alternatives doesn't outweigh the others
+func trainingText(tokens []parser.Token) string {
+	var words [][]byte
+	for _, tk := range tokens {
+		if len(tk.Alternatives) > 0 {
+			words = append(words, tk.Alternatives...)
+			continue
+		}
+		words = append(words, tk.Val)
+	}
+	return string(bytes.Join(words, []byte(" ")))
+}
+
+// learnTokens returns the expected items of the tokens of sample sid
+// and whether there's a keyword among them
+func (m *model) learnTokens(sid int, tokens []parser.Token) ([]item, bool, error) {
+	var exps []item
+	var hasAtLeastOneKey bool
+	l := len(tokens)
+	for i, tk := range tokens {
+		if tk.Kw {
+			hasAtLeastOneKey = true
+			mistypedField := true
+			for _, f := range m.fields {
+				if f.is(tk.Val) {
+					mistypedField = false
+					hinted, err := m.hint(f, string(tk.Hint))
+					if err != nil {
+						return nil, false, fmt.Errorf("sample#%d: %v", sid, err)
 					}
+					exps = append(exps, item{field: hinted, value: tk.Val, hint: string(tk.Hint)})
 				}
-				if mistypedField {
-					return fmt.Errorf("sample#%d: mistyped field %q", sid, tk.Val)
+			}
+			if mistypedField {
+				return nil, false, fmt.Errorf("sample#%d: mistyped field %q", sid, tk.Val)
+			}
+		} else {
+			if i+1 < l {
+				if tokens[i+1].Kw {
+					exps = append(exps, item{limit: true, value: tk.Val, alternatives: tk.Alternatives})
+					continue
 				}
-			} else {
-				if i+1 < l {
-					if tokens[i+1].Kw {
-						exps = append(exps, item{limit: true, value: tk.Val})
-						continue
-					}
+			}
+		}
+	}
+	return exps, hasAtLeastOneKey, nil
+}
+
+// match is the result of fitting an expression to a sample
+type match struct {
+	sample int
+	// optionals tells which optional groups of the sample are present
+	optionals []bool
+	score     int
+	// invalid is the number of captures that
+	// can't be converted to their fields
+	invalid  int
+	captures []capture
+}
+
+// capture is a value read from the tokens [start, end) of an expression
+type capture struct {
+	field      field
+	value      []byte
+	start, end int
+	// layout is the layout that parsed the value of time.Time fields
+	layout string
+}
+
+// expression is an expression split in tokens
+type expression struct {
+	text   []byte
+	tokens []parser.Token
+	// offsets are the byte offsets of the tokens in text
+	offsets []int
+	// valid caches whether the tokens of a span can be
+	// converted to the field of a keyword
+	valid map[span]bool
+}
+
+// span are the tokens [start, end) of an expression read
+// as the value of the keyword name with the hint
+type span struct {
+	name, hint string
+	start, end int
+}
+
+func newExpression(text []byte) *expression {
+	tokens := parser.Tokenize(text)
+	ex := &expression{
+		text:    text,
+		tokens:  tokens,
+		offsets: make([]int, len(tokens)),
+		valid:   make(map[span]bool),
+	}
+	var pos int
+	for i, t := range tokens {
+		// the tokens are read in order from text
+		pos += bytes.Index(text[pos:], t.Val)
+		ex.offsets[i] = pos
+		pos += len(t.Val)
+	}
+	return ex
+}
+
+// slice returns the text of the tokens [start, end) with the original spacing
+func (ex *expression) slice(start, end int) []byte {
+	return ex.text[ex.offsets[start] : ex.offsets[end-1]+len(ex.tokens[end-1].Val)]
+}
+
+func (m *model) selectBestSample(expr []byte) *match {
+	ex := newExpression(expr)
+
+	var best *match
+	for sid, variants := range m.expected {
+		for _, v := range variants {
+			mt := m.match(sid, v, ex)
+			for _, c := range mt.captures {
+				if m.set(reflect.New(m.tpy).Elem(), c.field, string(c.value)) != nil {
+					mt.invalid++
+				}
+			}
+			// the samples whose values are valid are preferred
+			if best == nil || mt.score-mt.invalid > best.score-best.invalid {
+				best = mt
+			}
+		}
+	}
+	return best
+}
+
+// match reads the values of the keywords of the variant v of sample sid from ex
+func (m *model) match(sid int, v variant, ex *expression) *match {
+	tokens := ex.tokens
+	mt := &match{sample: sid, optionals: v.optionals}
+	// limits of the sample in the order they appear in tokens
+	var found [][]byte
+	var last int
+expecteds:
+	for k := 0; k < len(v.items); k++ {
+		e := v.items[k]
+		// adjacent keywords, like {Amount} {Currency},
+		// share the tokens up to the next limit
+		run := k + 1
+		for !e.limit && run < len(v.items) && !v.items[run].limit {
+			run++
+		}
+		start := -1
+		for i := last; i < len(tokens); i++ {
+			if n := v.isLimit(tokens[i:]); n > 0 {
+				if start >= 0 {
+					// the limit is read by the next expected item
+					m.segment(mt, v.items[k:run], ex, start, i)
+					k = run - 1
+					last = i
+					continue expecteds
+				}
+				words := make([][]byte, n)
+				for j, t := range tokens[i : i+n] {
+					words[j] = t.Val
 				}
+				found = append(found, bytes.Join(words, []byte(" ")))
+				mt.score++
+				last = i + n
+				continue expecteds
+			}
+			if !e.limit && start < 0 {
+				start = i
 			}
 		}
-		if !hasAtLeastOneKey {
-			return fmt.Errorf("sample#%d: need at least one keyword", sid)
+		if start >= 0 {
+			m.segment(mt, v.items[k:run], ex, start, len(tokens))
 		}
-		m.expected[sid] = exps
+		// every token has been read
+		break
 	}
-	return nil
+	var limits []item
+	for _, e := range v.items {
+		if e.limit {
+			limits = append(limits, e)
+		}
+	}
+	if len(limits) <= len(found) {
+		for j := range limits {
+			if !limits[j].accepts(found[j]) {
+				return mt
+			}
+		}
+		mt.score++
+	}
+	return mt
 }
 
-func (m *model) selectBestSample(expr []byte) []item {
-	// slice [sample_id]score
-	scores := make([]int, len(m.samples))
+// segment captures the tokens [start, end) of ex as the values of the
+// adjacent keywords of run. The tokens are split where most values can
+// be converted to their fields, so a numeric field takes the number and
+// an enum field its allowed value, on ties the first keywords take more
+// tokens, a keyword may be left without tokens if there aren't enough.
+// Every keyword but the last takes up to maxAdjacent tokens, so the
+// split is found checking O(len(run)*(end-start)*maxAdjacent) values
+func (m *model) segment(mt *match, run []item, ex *expression, start, end int) {
+	if len(run) == 1 {
+		mt.add(run[0].field, ex, start, end)
+		return
+	}
+	// value returns the score of the tokens [a, b) as the value of run[j],
+	// a value that can be converted to its field is worth more than all
+	// the keywords of run with a value that can't
+	value := func(j, a, b int) int {
+		switch {
+		case a == b:
+			return 0
+		case m.valid(ex, run[j], a, b):
+			return len(run) + 2
+		}
+		return 1
+	}
+	// scores[j][c-start] is the best score of the values of run[j:]
+	// read from the tokens [c, end), or -1 if it isn't known yet
+	scores := make([][]int, len(run))
+	for j := range scores {
+		scores[j] = make([]int, end-start+1)
+		for c := range scores[j] {
+			scores[j][c] = -1
+		}
+	}
+	// stop returns the token after the last one a keyword
+	// followed by another one may take starting at c
+	stop := func(c int) int {
+		if c+maxAdjacent < end {
+			return c + maxAdjacent
+		}
+		return end
+	}
+	var best func(j, c int) int
+	best = func(j, c int) int {
+		if j == len(run)-1 {
+			return value(j, c, end)
+		}
+		if scores[j][c-start] < 0 {
+			for b := stop(c); b >= c; b-- {
+				if v := value(j, c, b) + best(j+1, b); v > scores[j][c-start] {
+					scores[j][c-start] = v
+				}
+			}
+		}
+		return scores[j][c-start]
+	}
+	for j, c := 0, start; j < len(run); j++ {
+		// on ties the first keywords take more tokens
+		b := end
+		if j < len(run)-1 {
+			b = stop(c)
+			for value(j, c, b)+best(j+1, b) != best(j, c) {
+				b--
+			}
+		}
+		if c < b {
+			mt.add(run[j].field, ex, c, b)
+		}
+		c = b
+	}
+}
 
-	tokens, _ := parser.ParseSample(0, expr)
+// valid returns true if the tokens [start, end) of ex can be converted
+// to the field of the keyword e, the results are cached in ex because
+// segment checks the same tokens for every split and variant
+func (m *model) valid(ex *expression, e item, start, end int) bool {
+	k := span{name: e.field.name, hint: e.hint, start: start, end: end}
+	v, ok := ex.valid[k]
+	if !ok {
+		v = m.set(reflect.New(m.tpy).Elem(), e.field, string(ex.slice(start, end))) == nil
+		ex.valid[k] = v
+	}
+	return v
+}
 
-	mapping := make([][]item, len(m.samples))
-	limitsOrder := make([][][]byte, len(m.samples)+1)
+// add captures the tokens [start, end) of ex as the value of f
+func (mt *match) add(f field, ex *expression, start, end int) {
+	mt.captures = append(mt.captures, capture{
+		field: f,
+		value: ex.slice(start, end),
+		start: start,
+		end:   end,
+	})
+}
 
-	for sid, exps := range m.expected {
-		var currentVal [][]byte
-		var reading bool
-		var lastToken int
-	expecteds:
-		for _, e := range exps {
-			// fmt.Printf("expecting: %s - limit: %v\n", e.value, e.limit)
-			if e.limit {
-				reading = false
-				limitsOrder[sid+1] = append(limitsOrder[sid+1], e.value)
+func (m *model) fit(expr string) (interface{}, *match, error) {
+	val := reflect.New(m.tpy)
+	var mt *match
+	if len(expr) > 0 {
+		mt = m.selectBestSample([]byte(expr))
+	}
+	var errs FieldErrors
+	captured := make(map[string]bool)
+	if mt != nil {
+		for i, c := range mt.captures {
+			captured[c.field.name] = true
+			err := m.set(val.Elem(), c.field, string(c.value))
+			if err == nil {
+				mt.captures[i].layout = m.layout(c)
 			} else {
-				reading = true
-			}
-			// fmt.Printf("reading: %v\n", reading)
-			for i := lastToken; i < len(tokens); i++ {
-				t := tokens[i]
-				// fmt.Printf("token: %s - isLimit: %v\n", t.Val, m.isLimit(t.Val, sid))
-				if m.isLimit(t.Val, sid) {
-					if sid == 0 {
-						limitsOrder[0] = append(limitsOrder[0], t.Val)
-					}
-					scores[sid]++
-					if len(currentVal) > 0 {
-						// fmt.Printf("appending: %s {%v}\n", bytes.Join(currentVal, []byte{' '}), e.field.name)
-						mapping[sid] = append(mapping[sid], item{field: e.field, value: bytes.Join(currentVal, []byte{' '})})
-						currentVal = currentVal[:0]
-						lastToken = i
-						continue expecteds
-					}
-					lastToken = i + 1
-					continue expecteds
-				} else {
-					if reading {
-						// fmt.Printf("adding: %s\n", t.Val)
-						currentVal = append(currentVal, t.Val)
-					}
-				}
+				errs = append(errs, &FieldError{
+					Field: c.field.name,
+					Value: string(c.value),
+					Type:  c.field.typ,
+					Err:   err,
+				})
 			}
-			if len(currentVal) > 0 {
-				// fmt.Printf("appending: %s {%v}\n", bytes.Join(currentVal, []byte{' '}), e.field.name)
-				mapping[sid] = append(mapping[sid], item{field: e.field, value: bytes.Join(currentVal, []byte{' '})})
+		}
+	}
+	for _, f := range m.fields {
+		if captured[f.name] {
+			continue
+		}
+		if f.def != nil {
+			// default values are validated by RegisterModel
+			m.set(val.Elem(), f, *f.def)
+		} else if f.required {
+			errs = append(errs, &FieldError{
+				Field: f.name,
+				Type:  f.typ,
+				Err:   ErrMissingField,
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return val.Interface(), mt, errs
+	}
+	return val.Interface(), mt, nil
+}
+
+// set converts s to the type of f and sets it in the struct v,
+// the values of slice fields are split by the model separators
+// and pointer fields are only allocated if s can be converted
+func (m *model) set(v reflect.Value, f field, s string) error {
+	target := fieldByIndex(v, f.index)
+	fv := target
+	if f.ptr {
+		fv = reflect.New(f.typ.Elem()).Elem()
+	}
+	if f.slice {
+		elems := m.split(s)
+		sv := reflect.MakeSlice(fv.Type(), len(elems), len(elems))
+		for i, e := range elems {
+			err := m.setValue(sv.Index(i), f, e)
+			if err != nil {
+				return err
 			}
 		}
-		// fmt.Printf("\n\n")
+		fv.Set(sv)
+	} else {
+		err := m.setValue(fv, f, s)
+		if err != nil {
+			return err
+		}
+	}
+	if f.ptr {
+		target.Set(fv.Addr())
+	}
+	return nil
+}
+
+// setValue converts s to the type of fv, the kind of f, the
+// values are trimmed to the match of the pattern of f and the
+// values of enum fields are resolved before converting
+func (m *model) setValue(fv reflect.Value, f field, s string) error {
+	if f.pattern != nil {
+		loc := f.pattern.FindStringIndex(s)
+		if loc == nil {
+			return fmt.Errorf("%q doesn't match %v", s, f.pattern)
+		}
+		s = s[loc[0]:loc[1]]
 	}
-order:
-	for i := 1; i < len(limitsOrder); i++ {
-		if len(limitsOrder[0]) < len(limitsOrder[i]) {
-			continue order
+	if f.enum != nil {
+		v, err := f.enum.resolve(s)
+		if err != nil {
+			return err
 		}
-		for j := range limitsOrder[i] {
-			if !bytes.Equal(limitsOrder[i][j], limitsOrder[0][j]) {
-				continue order
+		s = v
+	}
+	switch t := f.kind.(type) {
+	case reflect.Kind:
+		switch t {
+		case reflect.String:
+			if f.as != nil {
+				err := m.setValue(reflect.New(f.as.typ).Elem(), *f.as, s)
+				if err != nil {
+					return err
+				}
+			}
+			fv.SetString(s)
+		case reflect.Bool:
+			bw := m.boolWords
+			if f.boolWords != nil {
+				bw = *f.boolWords
+			}
+			v, err := bw.parse(s)
+			if err != nil {
+				return err
+			}
+			fv.SetBool(v)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			v, err := strconv.ParseUint(m.numeral(s), 10, fv.Type().Bits())
+			if err != nil {
+				return err
 			}
+			fv.SetUint(v)
+		case reflect.Float32, reflect.Float64:
+			v, err := strconv.ParseFloat(m.numeral(s), fv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			fv.SetFloat(v)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			v, err := strconv.ParseInt(m.numeral(s), 10, fv.Type().Bits())
+			if err != nil {
+				return err
+			}
+			fv.SetInt(v)
+		}
+	case time.Time:
+		v, _, err := m.parseTime(f, s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(v))
+	case time.Duration:
+		v, err := parseDuration(s)
+		if err != nil {
+			return err
 		}
-		scores[i-1]++
+		fv.Set(reflect.ValueOf(v))
+	case reflect.Type:
+		return m.convert(fv, t, s)
 	}
+	return nil
+}
 
-	// fmt.Printf("orders: %s\n\n", limitsOrder)
-	// fmt.Printf("scores: %v\n", scores)
+// parseTime parses s with the layouts of f, or the model time formats
+// if f doesn't have any, it returns the layout that parsed s.
+// If none of them parses s it's parsed as a date written in english
+func (m *model) parseTime(f field, s string) (time.Time, string, error) {
+	layouts := m.timeFormats
+	if len(f.layouts) > 0 {
+		layouts = f.layouts
+	}
+	var first error
+	for _, layout := range layouts {
+		v, err := time.ParseInLocation(layout, s, m.timeLocation)
+		if err == nil {
+			return v, layout, nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if v, err := parseNaturalTime(s, m.now().In(m.timeLocation)); err == nil {
+		return v, "", nil
+	}
+	if len(layouts) == 1 {
+		return time.Time{}, "", first
+	}
+	return time.Time{}, "", fmt.Errorf("%q doesn't match any of the layouts %q", s, layouts)
+}
 
-	bestMapping := selectBestMapping(scores)
-	if bestMapping == -1 {
-		return nil
+// layout returns the layout that parses the value of c if
+// it's a time.Time field, or the one of its first element
+func (m *model) layout(c capture) string {
+	if _, ok := c.field.kind.(time.Time); !ok || c.field.enum != nil {
+		return ""
+	}
+	s := string(c.value)
+	if c.field.slice {
+		elems := m.split(s)
+		if len(elems) == 0 {
+			return ""
+		}
+		s = elems[0]
+	}
+	_, layout, _ := m.parseTime(c.field, s)
+	return layout
+}
+
+// FieldError is returned when a value read from an
+// expression can't be converted to the type of its field
+type FieldError struct {
+	// Field is the keyword of the field
+	Field string
+	// Value is the text read from the expression
+	Value string
+	// Type is the type of the field
+	Type reflect.Type
+	// Err is the error returned by the conversion
+	Err error
+}
+
+// ErrMissingField is the error of a *FieldError
+// when a required field isn't in the expression
+var ErrMissingField = errors.New("missing required field")
+
+func (e *FieldError) Error() string {
+	if e.Err == ErrMissingField {
+		return fmt.Sprintf("field %s: %v", e.Field, e.Err)
 	}
-	return mapping[bestMapping]
+	return fmt.Sprintf("field %s: can't convert %q to %v: %v", e.Field, e.Value, e.Type, e.Err)
 }
 
-func selectBestMapping(scores []int) int {
-	bestScore, bestMapping := -1, -1
-	for id, score := range scores {
-		if score > bestScore {
-			bestScore = score
-			bestMapping = id
+// Unwrap returns the error returned by the conversion
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// FieldErrors contains every *FieldError that occurred
+// while filling a model
+type FieldErrors []*FieldError
+
+func (e FieldErrors) Error() string {
+	var buf bytes.Buffer
+	for i, err := range e {
+		if i > 0 {
+			buf.WriteRune('\n')
 		}
+		buf.WriteString(err.Error())
 	}
-	return bestMapping
+	return buf.String()
 }
 
-func (m *model) fit(expr string) interface{} {
-	val := reflect.New(m.tpy)
-	if len(expr) == 0 {
-		return val.Interface()
-	}
-	exps := m.selectBestSample([]byte(expr))
-	if len(exps) > 0 {
-		for _, e := range exps {
-			switch t := e.field.kind.(type) {
-			case reflect.Kind:
-				switch t {
-				case reflect.String:
-					val.Elem().Field(e.field.index).SetString(string(e.value))
-				case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-					v, _ := strconv.ParseUint(string(e.value), 10, 0)
-					val.Elem().Field(e.field.index).SetUint(v)
-				case reflect.Float32, reflect.Float64:
-					v, _ := strconv.ParseFloat(string(e.value), 64)
-					val.Elem().Field(e.field.index).SetFloat(v)
-				case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-					v, _ := strconv.ParseInt(string(e.value), 10, 0)
-					val.Elem().Field(e.field.index).SetInt(v)
+// Unwrap returns the errors within e, so errors.As can
+// extract a *FieldError from FieldErrors
+func (e FieldErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// isLimit returns the number of tokens of the longest
+// limit of v at the start of tokens, 0 if there isn't any
+func (v variant) isLimit(tokens []parser.Token) int {
+	var n int
+	for _, e := range v.items {
+		if !e.limit {
+			continue
+		}
+	alternatives:
+		for _, alt := range e.limits() {
+			words := bytes.Fields(alt)
+			if len(words) <= n || len(words) > len(tokens) {
+				continue
+			}
+			for i, w := range words {
+				if !bytes.Equal(w, tokens[i].Val) {
+					continue alternatives
 				}
-			case time.Time:
-				v, _ := time.ParseInLocation(m.timeFormat, string(e.value), m.timeLocation)
-				val.Elem().Field(e.field.index).Set(reflect.ValueOf(v))
-			case time.Duration:
-				v, _ := time.ParseDuration(string(e.value))
-				val.Elem().Field(e.field.index).Set(reflect.ValueOf(v))
 			}
+			n = len(words)
 		}
 	}
-	return val.Interface()
+	return n
 }
 
-// isLimit returns true if s is a limit on expected[id]
-func (m *model) isLimit(s []byte, id int) bool {
-	for _, e := range m.expected[id] {
-		if bytes.Equal(e.value, s) {
+// limits returns the alternatives of the limit e, a
+// limit that isn't an alternation is its only alternative
+func (e item) limits() [][]byte {
+	if len(e.alternatives) > 0 {
+		return e.alternatives
+	}
+	return [][]byte{e.value}
+}
+
+// accepts returns true if s, with its words
+// separated by a space, is an alternative of e
+func (e item) accepts(s []byte) bool {
+	for _, alt := range e.limits() {
+		if bytes.Equal(alt, s) {
 			return true
 		}
 	}
 	return false
 }
 
+// clone returns a copy of m that can learn without modifying m
+func (m *model) clone() *model {
+	c := *m
+	c.expected = make([][]variant, len(m.samples))
+	c.texts = make([]string, len(m.samples))
+	return &c
+}
+
+func (bw boolWords) validate() error {
+	if len(bw.truthy) == 0 || len(bw.falsy) == 0 {
+		return errors.New("bool words can't be empty")
+	}
+	for _, t := range bw.truthy {
+		for _, f := range bw.falsy {
+			if strings.EqualFold(t, f) {
+				return fmt.Errorf("bool word %q can't be true and false", t)
+			}
+		}
+	}
+	for _, w := range append(bw.truthy, bw.falsy...) {
+		if strings.TrimSpace(w) == "" {
+			return errors.New("bool words can't be blank")
+		}
+	}
+	return nil
+}
+
+// parse returns the value of s, if s isn't a bool word
+// the first bool word among the words of s is used
+func (bw boolWords) parse(s string) (bool, error) {
+	if v, ok := bw.word(s); ok {
+		return v, nil
+	}
+	for _, w := range strings.Fields(s) {
+		if v, ok := bw.word(w); ok {
+			return v, nil
+		}
+	}
+	return false, fmt.Errorf("%q isn't any of %v or %v", s, bw.truthy, bw.falsy)
+}
+
+func (bw boolWords) word(s string) (v bool, ok bool) {
+	for _, t := range bw.truthy {
+		if strings.EqualFold(s, t) {
+			return true, true
+		}
+	}
+	for _, f := range bw.falsy {
+		if strings.EqualFold(s, f) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// split splits s by the model separators, the empty elements are left out
+func (m *model) split(s string) []string {
+	var elems, cur []string
+	flush := func() {
+		if len(cur) > 0 {
+			elems = append(elems, strings.Join(cur, " "))
+			cur = nil
+		}
+	}
+NextWord:
+	for _, w := range strings.Fields(s) {
+		parts := []string{w}
+		for _, sep := range m.separators {
+			if isWord(sep) {
+				if strings.EqualFold(w, sep) {
+					flush()
+					continue NextWord
+				}
+				continue
+			}
+			var split []string
+			for _, p := range parts {
+				split = append(split, strings.Split(p, sep)...)
+			}
+			parts = split
+		}
+		for i, p := range parts {
+			if i > 0 {
+				flush()
+			}
+			if p != "" {
+				cur = append(cur, p)
+			}
+		}
+	}
+	flush()
+	return elems
+}
+
+// isWord returns true if s is made of letters only
+func isWord(s string) bool {
+	return strings.IndexFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) == -1
+}
+
 // setSample converts the []string samples to [][]byte
 func (m *model) setSamples(samples []string) {
 	for _, s := range samples {
@@ -2,11 +2,15 @@ package nlp
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
-
-	"github.com/cdipaolo/goml/text"
 )
 
 func failTest(t *testing.T, err error) {
@@ -123,7 +127,6 @@ func TestNL_P(t *testing.T) {
 func TestNL_RegisterModel(t *testing.T) {
 	type fields struct {
 		models []*model
-		naive  *text.NaiveBayes
 		Output *bytes.Buffer
 	}
 	type args struct {
@@ -174,12 +177,128 @@ func TestNL_RegisterModel(t *testing.T) {
 			}},
 			false,
 		},
+		{
+			"tags",
+			fields{},
+			args{struct {
+				Name     string        `nlp:"name,alias=title|song,required"`
+				Released time.Time     `nlp:"released,layout=Jan_02,_2006,default=Jan_01,_2000"`
+				Dur      time.Duration `nlp:",default=3m"`
+				Ignored  string        `nlp:"-"`
+				Other    []string      `nlp:"-"`
+			}{}, []string{""}, nil},
+			false,
+		},
+		{
+			"unknown tag option",
+			fields{},
+			args{struct {
+				Name string `nlp:"name,size=3"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"tag option without value",
+			fields{},
+			args{struct {
+				Name string `nlp:"name,alias"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"tag option with value",
+			fields{},
+			args{struct {
+				Name string `nlp:"name,required=yes"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"invalid keyword",
+			fields{},
+			args{struct {
+				Name string `nlp:"song name"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"keyword with colon",
+			fields{},
+			args{struct {
+				Name string `nlp:"song:name"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"duplicated keyword",
+			fields{},
+			args{struct {
+				Name   string `nlp:"name"`
+				Artist string `nlp:"artist,alias=name"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"layout in non-time field",
+			fields{},
+			args{struct {
+				Name string `nlp:"name,layout=2006"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"invalid default",
+			fields{},
+			args{struct {
+				Age int `nlp:"age,default=old"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"required with default",
+			fields{},
+			args{struct {
+				Age int `nlp:"age,required,default=3"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"bool words in non-bool field",
+			fields{},
+			args{struct {
+				Name string `nlp:"name,true=si"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"bool words in both sets",
+			fields{},
+			args{struct {
+				On bool `nlp:"on,true=si|no,false=no"`
+			}{}, []string{""}, nil},
+			true,
+		},
+		{
+			"bool default",
+			fields{},
+			args{struct {
+				On bool `nlp:"on,true=si,default=si"`
+			}{}, []string{""}, nil},
+			false,
+		},
+		{
+			"tag in unsupported type",
+			fields{},
+			args{struct {
+				Names map[string]int `nlp:"names"`
+			}{}, []string{""}, nil},
+			true,
+		},
 	}
 	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			nl := &NL{
 				models: tt.fields.models,
-				naive:  tt.fields.naive,
 				Output: tt.fields.Output,
 			}
 			if err := nl.RegisterModel(tt.args.i, tt.args.samples, tt.args.ops...); (err != nil) != tt.wantErr {
@@ -192,7 +311,6 @@ func TestNL_RegisterModel(t *testing.T) {
 func TestNL_Learn(t *testing.T) {
 	type fields struct {
 		models []*model
-		naive  *text.NaiveBayes
 		Output *bytes.Buffer
 	}
 	type T struct {
@@ -244,12 +362,35 @@ func TestNL_Learn(t *testing.T) {
 			},
 			true,
 		},
+		{
+			"optional group with no keys",
+			fields{
+				models: []*model{
+					{
+						samples: [][]byte{[]byte("Hello [there]")},
+					},
+				},
+				Output: bytes.NewBufferString(""),
+			},
+			true,
+		},
+		{
+			"too many optional groups",
+			fields{
+				models: []*model{
+					{
+						samples: [][]byte{[]byte("[a] [b] [c] [d] [e] [f] [g] [h] [i]")},
+					},
+				},
+				Output: bytes.NewBufferString(""),
+			},
+			true,
+		},
 	}
 	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			nl := &NL{
 				models: tt.fields.models,
-				naive:  tt.fields.naive,
 				Output: tt.fields.Output,
 			}
 			if err := nl.Learn(); (err != nil) != tt.wantErr {
@@ -270,8 +411,8 @@ func TestWithTimeFormat(t *testing.T) {
 		wantErr bool
 	}{
 		{
-			"invalid format",
-			args{"2006 01 02", &model{}},
+			"blank format",
+			args{" ", &model{}},
 			true,
 		},
 		{
@@ -279,6 +420,11 @@ func TestWithTimeFormat(t *testing.T) {
 			args{"2006", &model{}},
 			false,
 		},
+		{
+			"format with spaces",
+			args{"Jan 2 2006 3:04 PM", &model{}},
+			false,
+		},
 	}
 	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -320,3 +466,1276 @@ func TestWithTimeLocation(t *testing.T) {
 		})
 	}
 }
+
+func TestNL_Parse(t *testing.T) {
+	type Song struct {
+		Name   string
+		Artist string
+	}
+	type Timer struct {
+		Dur time.Duration
+	}
+
+	nl := New()
+
+	_, err := nl.Parse("play King by Lauren Aquilina")
+	if err == nil {
+		t.Error("NL.Parse() before learning must fail")
+	}
+
+	err = nl.RegisterModel(Song{}, []string{
+		"play {Name} by {Artist}",
+		"play {Name}",
+	})
+	failTest(t, err)
+
+	err = nl.RegisterModel(Timer{}, []string{
+		"set a timer for {Dur}",
+		"timer {Dur}",
+	})
+	failTest(t, err)
+
+	err = nl.Learn()
+	failTest(t, err)
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *Result
+	}{
+		0: {
+			"song",
+			"hello play King by Lauren Aquilina",
+			&Result{
+				Value:  &Song{Name: "King", Artist: "Lauren Aquilina"},
+				Model:  0,
+				Type:   reflect.TypeOf(Song{}),
+				Sample: "play {Name} by {Artist}",
+				Score:  3,
+				Captures: []Capture{
+					{Field: "Name", Value: "King", Start: 2, End: 3},
+					{Field: "Artist", Value: "Lauren Aquilina", Start: 4, End: 6},
+				},
+			},
+		},
+		1: {
+			"timer",
+			"please set a timer for 4h2m",
+			&Result{
+				Value:  &Timer{Dur: 4*time.Hour + 2*time.Minute},
+				Model:  1,
+				Type:   reflect.TypeOf(Timer{}),
+				Sample: "set a timer for {Dur}",
+				Score:  2,
+				Captures: []Capture{
+					{Field: "Dur", Value: "4h2m", Start: 5, End: 6},
+				},
+			},
+		},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := nl.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("test#%d: NL.Parse() error = %v", i, err)
+			}
+			if res.Probability <= 0 || res.Probability > 1 {
+				t.Errorf("test#%d: got probability %v", i, res.Probability)
+			}
+			res.Probability = 0
+			if !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_FieldErrors(t *testing.T) {
+	type T struct {
+		Int   int
+		Int8  int8
+		Uint  uint16
+		Float float32
+		Time  time.Time
+		Dur   time.Duration
+	}
+
+	nl := New()
+
+	err := nl.RegisterModel(T{}, []string{
+		"int {Int}",
+		"int8 {Int8}",
+		"uint {Uint}",
+		"float {Float}",
+		"time {Time}",
+		"dur {Dur}",
+	})
+	failTest(t, err)
+
+	err = nl.Learn()
+	failTest(t, err)
+
+	cases := []struct {
+		name       string
+		expression string
+		want       []string
+	}{
+		0: {"valid", "int 42", nil},
+		1: {"int", "int forty days", []string{"Int"}},
+		2: {"int8 overflow", "int8 300", []string{"Int8"}},
+		3: {"uint16 overflow", "uint 70000", []string{"Uint"}},
+		4: {"float", "float many", []string{"Float"}},
+		5: {"time", "time someday", []string{"Time"}},
+		6: {"duration", "dur forever", []string{"Dur"}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := nl.Parse(tt.expression)
+			if res == nil {
+				t.Fatalf("test#%d: NL.Parse() returned a nil *Result", i)
+			}
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("test#%d: NL.Parse() error = %v", i, err)
+				}
+				return
+			}
+			var fe *FieldError
+			if !errors.As(err, &fe) {
+				t.Fatalf("test#%d: NL.Parse() error = %v, want *FieldError", i, err)
+			}
+			var errs FieldErrors
+			errors.As(err, &errs)
+			var got []string
+			for _, e := range errs {
+				got = append(got, e.Field)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("test#%d: got fields %v want %v", i, got, tt.want)
+			}
+			if !reflect.DeepEqual(res.Value, &T{}) {
+				t.Errorf("test#%d: got %v want an empty model", i, res.Value)
+			}
+		})
+	}
+}
+
+func TestNL_PTopN(t *testing.T) {
+	type Song struct {
+		Name string
+	}
+	type Timer struct {
+		Dur time.Duration
+	}
+	type Alarm struct {
+		At string
+	}
+
+	nl := New()
+
+	if res := nl.PTopN("play King", 2); res != nil {
+		t.Errorf("NL.PTopN() before learning = %v, want nil", res)
+	}
+
+	failTest(t, nl.RegisterModel(Song{}, []string{"play {Name}", "play the song {Name}"}))
+	failTest(t, nl.RegisterModel(Timer{}, []string{"set a timer for {Dur}", "timer {Dur}"}))
+	failTest(t, nl.RegisterModel(Alarm{}, []string{"set an alarm at {At}", "wake me up at {At}"}))
+	failTest(t, nl.Learn())
+
+	tests := []struct {
+		name       string
+		expression string
+		n          int
+		want       []interface{}
+	}{
+		0: {"none", "play King", 0, nil},
+		1: {"best", "play King", 1, []interface{}{&Song{Name: "King"}}},
+		2: {"top 2", "set a timer for 1h", 2, []interface{}{&Timer{Dur: time.Hour}, &Alarm{}}},
+		3: {"more than models", "play King", 5, []interface{}{&Song{Name: "King"}, nil, nil}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := nl.PTopN(tt.expression, tt.n)
+			if len(res) != len(tt.want) {
+				t.Fatalf("[%d] NL.PTopN() returned %d results, want %d", i, len(res), len(tt.want))
+			}
+			var sum float64
+			for j, r := range res {
+				if j > 0 && r.Probability > res[j-1].Probability {
+					t.Errorf("[%d] NL.PTopN() results aren't sorted by probability", i)
+				}
+				sum += r.Probability
+				if tt.want[j] != nil && !reflect.DeepEqual(r.Value, tt.want[j]) {
+					t.Errorf("[%d] NL.PTopN()[%d] = %v, want %v", i, j, r.Value, tt.want[j])
+				}
+			}
+			if len(res) == 3 && math.Abs(sum-1) > 1e-9 {
+				t.Errorf("[%d] NL.PTopN() probabilities sum %v, want 1", i, sum)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_NoMatch(t *testing.T) {
+	type Song struct {
+		Name string
+	}
+	type Timer struct {
+		Dur time.Duration
+	}
+	tests := []struct {
+		name       string
+		ops        []Option
+		expression string
+		wantErr    error
+	}{
+		0: {
+			"no options",
+			nil,
+			"what's the weather like",
+			nil,
+		},
+		1: {
+			"min score",
+			[]Option{WithMinScore(1)},
+			"what's the weather like",
+			ErrNoMatch,
+		},
+		2: {
+			"min score reached",
+			[]Option{WithMinScore(1)},
+			"play King",
+			nil,
+		},
+		3: {
+			"min probability",
+			[]Option{WithMinProbability(1.1)},
+			"play King",
+			ErrNoMatch,
+		},
+		4: {
+			"no match samples",
+			[]Option{WithNoMatchSamples("what's the weather like", "how is the weather today")},
+			"what's the weather like in Paris",
+			ErrNoMatch,
+		},
+		5: {
+			"no match samples with a match",
+			[]Option{WithNoMatchSamples("what's the weather like", "how is the weather today")},
+			"play King",
+			nil,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := New(tt.ops...)
+			failTest(t, nl.RegisterModel(Song{}, []string{"play {Name}", "play the song {Name}"}))
+			failTest(t, nl.RegisterModel(Timer{}, []string{"set a timer for {Dur}", "timer {Dur}"}))
+			failTest(t, nl.Learn())
+			res, err := nl.Parse(tt.expression)
+			if err != tt.wantErr {
+				t.Fatalf("[%d] NL.Parse() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if v := nl.P(tt.expression); (v == nil) != (tt.wantErr == ErrNoMatch) {
+				t.Errorf("[%d] NL.P() = %v", i, v)
+			}
+			if err == ErrNoMatch && res != nil {
+				t.Errorf("[%d] NL.Parse() = %v, want nil", i, res)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	type Song struct {
+		Name   string
+		Artist string
+	}
+	type Timer struct {
+		Dur time.Duration
+	}
+
+	nl := New()
+
+	_, err := Register[int](nl, []string{"int {Int}"})
+	if err == nil {
+		t.Error("Register() with a non-struct type must fail")
+	}
+
+	songs, err := Register[Song](nl, []string{"play {Name} by {Artist}", "play {Name}"})
+	failTest(t, err)
+
+	timers, err := Register[Timer](nl, []string{"set a timer for {Dur}", "timer {Dur}"})
+	failTest(t, err)
+
+	failTest(t, nl.Learn())
+
+	song, ok := songs.Parse("play King by Lauren Aquilina")
+	if !ok || !reflect.DeepEqual(song, &Song{Name: "King", Artist: "Lauren Aquilina"}) {
+		t.Errorf("Model[Song].Parse() = %v, %v", song, ok)
+	}
+
+	if timer, ok := timers.Parse("play King by Lauren Aquilina"); ok || timer != nil {
+		t.Errorf("Model[Timer].Parse() = %v, %v, want nil, false", timer, ok)
+	}
+
+	timer, ok := timers.Parse("set a timer for 1h")
+	if !ok || timer.Dur != time.Hour {
+		t.Errorf("Model[Timer].Parse() = %v, %v", timer, ok)
+	}
+}
+
+func TestNL_Concurrent(t *testing.T) {
+	type Song struct {
+		Name string
+	}
+	type Timer struct {
+		Dur time.Duration
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Song{}, []string{"play {Name}", "play the song {Name}"}))
+	failTest(t, nl.Learn())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if res, err := nl.Parse("play King"); err != nil || res.Value == nil {
+					t.Errorf("NL.Parse() = %v, %v", res, err)
+					return
+				}
+				nl.PTopN("set a timer for 1h", 2)
+			}
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		failTest(t, nl.RegisterModel(Timer{}, []string{"set a timer for {Dur}", "timer {Dur}"}))
+		failTest(t, nl.Learn())
+	}
+	wg.Wait()
+
+	if res := nl.P("set a timer for 1h"); !reflect.DeepEqual(res, &Timer{Dur: time.Hour}) {
+		t.Errorf("NL.P() = %v, want %v", res, &Timer{Dur: time.Hour})
+	}
+}
+
+func TestNL_P_Tags(t *testing.T) {
+	type Song struct {
+		Name       string    `nlp:"name,alias=song,required"`
+		Artist     string    `nlp:"by"`
+		ReleasedAt time.Time `nlp:"released,alias=year,layout=2006"`
+		Plays      int       `nlp:"plays,default=1"`
+		Ignored    string    `nlp:"-"`
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Song{}, []string{"play {Ignored}"}))
+	if err := nl.Learn(); err == nil {
+		t.Error("NL.Learn() with an ignored field in a sample must fail")
+	}
+
+	nl = New()
+	failTest(t, nl.RegisterModel(Song{}, []string{
+		"play {name} by {by}",
+		"play the song {song} repeat {plays}",
+		"play something from {released}",
+		"play something released in {year}",
+	}))
+	failTest(t, nl.Learn())
+
+	rel, err := time.ParseInLocation("2006", "1999", time.Local)
+	failTest(t, err)
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *Song
+		wantErr    error
+	}{
+		0: {
+			"keywords",
+			"play King by Lauren Aquilina",
+			&Song{Name: "King", Artist: "Lauren Aquilina", Plays: 1},
+			nil,
+		},
+		1: {
+			"alias",
+			"play the song King repeat 3",
+			&Song{Name: "King", Plays: 3},
+			nil,
+		},
+		2: {
+			"layout",
+			"anything from 1999",
+			&Song{ReleasedAt: rel, Plays: 1},
+			ErrMissingField,
+		},
+		3: {
+			"layout alias",
+			"anything released in 1999",
+			&Song{ReleasedAt: rel, Plays: 1},
+			ErrMissingField,
+		},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := nl.Parse(tt.expression)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("test#%d: NL.Parse() error = %v, want %v", i, err, tt.wantErr)
+			}
+			if res == nil || !reflect.DeepEqual(res.Value, tt.want) {
+				t.Errorf("test#%d: got %v want %v", i, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_P_Nested(t *testing.T) {
+	type Person struct {
+		Name string
+		Age  int
+	}
+	type Place struct {
+		City string `nlp:"city"`
+	}
+	type Node struct {
+		Val string
+		// recursive types are walked once
+		Next *Node
+	}
+	type Booking struct {
+		Place
+		*Node  `nlp:"node"`
+		Guest  Person
+		Host   *Person `nlp:"host"`
+		Nights int
+		When   time.Time
+		City   string `nlp:"-"`
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Booking{}, []string{
+		"book a room for {Guest.Name} aged {Guest.Age} in {city}",
+		"book nights {Nights} host {host.Name}",
+		"book the node {node.Val}",
+	}))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *Booking
+	}{
+		0: {
+			"nested and embedded",
+			"book a room for John Doe aged 42 in Paris",
+			&Booking{Guest: Person{Name: "John Doe", Age: 42}, Place: Place{City: "Paris"}},
+		},
+		1: {
+			"nested pointer",
+			"book nights 3 host Jane",
+			&Booking{Nights: 3, Host: &Person{Name: "Jane"}},
+		},
+		2: {
+			"embedded pointer",
+			"book the node first",
+			&Booking{Node: &Node{Val: "first"}},
+		},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := nl.P(tt.expression); !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_P_Slices(t *testing.T) {
+	type Pizza struct {
+		Toppings []string
+		Sizes    []int
+		Times    []time.Time `nlp:"times,layout=15:04"`
+	}
+
+	tim, err := time.ParseInLocation("15:04", "18:30", time.Local)
+	failTest(t, err)
+
+	tests := []struct {
+		name       string
+		ops        []ModelOption
+		expression string
+		want       *Pizza
+		wantErr    bool
+	}{
+		0: {
+			"default separators",
+			nil,
+			"pizza with ham, olives and black mushrooms",
+			&Pizza{Toppings: []string{"ham", "olives", "black mushrooms"}},
+			false,
+		},
+		1: {
+			"word separators",
+			nil,
+			"pizza with ham or olives AND mushrooms",
+			&Pizza{Toppings: []string{"ham", "olives", "mushrooms"}},
+			false,
+		},
+		2: {
+			"custom separators",
+			[]ModelOption{WithSeparators(";", "y")},
+			"pizza with ham;olives y mushrooms, cheese",
+			&Pizza{Toppings: []string{"ham", "olives", "mushrooms, cheese"}},
+			false,
+		},
+		3: {
+			"ints",
+			nil,
+			"sizes 1,2 and 3",
+			&Pizza{Sizes: []int{1, 2, 3}},
+			false,
+		},
+		4: {
+			"invalid element",
+			nil,
+			"sizes 1, many and 3",
+			&Pizza{},
+			true,
+		},
+		5: {
+			"times",
+			nil,
+			"deliver at 18:30 or 18:30",
+			&Pizza{Times: []time.Time{tim, tim}},
+			false,
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := New()
+			failTest(t, nl.RegisterModel(Pizza{}, []string{
+				"pizza with {Toppings}",
+				"sizes {Sizes}",
+				"deliver at {times}",
+			}, tt.ops...))
+			failTest(t, nl.Learn())
+			res, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("[%d] NL.Parse() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(res.Value, tt.want) {
+				t.Errorf("[%d] got %v want %v", i, res.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithSeparators(t *testing.T) {
+	tests := []struct {
+		name    string
+		seps    []string
+		wantErr bool
+	}{
+		{"empty", nil, true},
+		{"blank", []string{",", " "}, true},
+		{"valid", []string{",", "and"}, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := WithSeparators(tt.seps...)
+			if err := op(&model{}); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] WithSeparators() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNL_P_Pointers(t *testing.T) {
+	type T struct {
+		String *string
+		Int    *int
+		Time   *time.Time `nlp:"Time,layout=2006"`
+		Dur    *time.Duration
+		Ints   *[]int
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"string {String}",
+		"int {Int}",
+		"time {Time}",
+		"dur {Dur}",
+		"ints {Ints}",
+		"string {String} int {Int}",
+	}))
+	failTest(t, nl.Learn())
+
+	str, i := "Hello World", 0
+	tim, err := time.ParseInLocation("2006", "1999", time.Local)
+	failTest(t, err)
+	dur := time.Hour
+	ints := []int{1, 2}
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+	}{
+		0: {"none", "string", &T{}},
+		1: {"string", "string Hello World", &T{String: &str}},
+		2: {"zero", "int 0", &T{Int: &i}},
+		3: {"invalid", "int nothing", &T{}},
+		4: {"time", "time 1999", &T{Time: &tim}},
+		5: {"duration", "dur 1h", &T{Dur: &dur}},
+		6: {"slice", "ints 1, 2", &T{Ints: &ints}},
+		7: {"string int", "string Hello World int 0", &T{String: &str, Int: &i}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := nl.P(tt.expression); !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_P_Bools(t *testing.T) {
+	type T struct {
+		On    bool
+		Lit   bool  `nlp:"lit,true=encendidas,false=apagadas"`
+		Fans  *bool `nlp:"fans"`
+		Flags []bool
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"turn {On} the lights",
+		"luces {lit}",
+		"fans {fans}",
+		"flags {Flags}",
+	}))
+	failTest(t, nl.Learn())
+
+	yes, no := true, false
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+	}{
+		0: {"on", "turn on the lights", &T{On: true}},
+		1: {"off", "turn off the lights", &T{}},
+		2: {"case", "turn ON the lights", &T{On: true}},
+		3: {"tag true", "luces encendidas", &T{Lit: true}},
+		4: {"tag false", "luces apagadas", &T{}},
+		5: {"tag default words", "luces on", &T{}},
+		6: {"pointer true", "fans enable", &T{Fans: &yes}},
+		7: {"pointer false", "fans disable", &T{Fans: &no}},
+		8: {"pointer invalid", "fans maybe", &T{}},
+		9: {"slice", "flags yes, no and true", &T{Flags: []bool{true, false, true}}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if res := nl.P(tt.expression); !reflect.DeepEqual(res, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, res, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithBoolWords(t *testing.T) {
+	tests := []struct {
+		name          string
+		truthy, falsy []string
+		wantErr       bool
+	}{
+		{"empty true", nil, []string{"no"}, true},
+		{"empty false", []string{"si"}, nil, true},
+		{"blank", []string{"si", " "}, []string{"no"}, true},
+		{"both", []string{"si", "no"}, []string{"NO"}, true},
+		{"valid", []string{"si"}, []string{"no"}, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := WithBoolWords(tt.truthy, tt.falsy)
+			if err := op(&model{}); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] WithBoolWords() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+type priority int
+
+func (p *priority) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*p = 1
+	case "high":
+		*p = 2
+	default:
+		return fmt.Errorf("unknown priority %q", text)
+	}
+	return nil
+}
+
+type sku struct {
+	Category string
+	Number   int
+}
+
+func TestNL_P_Converters(t *testing.T) {
+	type T struct {
+		Priority priority
+		SKU      sku
+		SKUs     []sku
+		Amount   *big.Int
+		Upper    string `nlp:"upper"`
+	}
+
+	skuConverter := func(s string) (interface{}, error) {
+		var v sku
+		_, err := fmt.Sscanf(s, "%3s-%d", &v.Category, &v.Number)
+		return v, err
+	}
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"priority {Priority}",
+		"item {SKU}",
+		"items {SKUs}",
+		"amount {Amount}",
+		"shout {upper}",
+	}, WithConverter(reflect.TypeOf(sku{}), skuConverter),
+		WithConverter(reflect.TypeOf(""), func(s string) (interface{}, error) {
+			return strings.ToUpper(s), nil
+		})))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantErr    bool
+	}{
+		0: {"text unmarshaler", "priority high", &T{Priority: 2}, false},
+		1: {"text unmarshaler error", "priority urgent", &T{}, true},
+		2: {"converter", "item ABC-12", &T{SKU: sku{"ABC", 12}}, false},
+		3: {"converter error", "item ABC", &T{}, true},
+		4: {"converter slice", "items ABC-1 and DEF-2", &T{SKUs: []sku{{"ABC", 1}, {"DEF", 2}}}, false},
+		5: {"pointer", "amount 123456789012345678901234567890", &T{Amount: func() *big.Int {
+			v, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+			return v
+		}()}, false},
+		6: {"override", "shout hello", &T{Upper: "HELLO"}, false},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: got err %v wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithConverter(t *testing.T) {
+	conv := func(s string) (interface{}, error) { return s, nil }
+	tests := []struct {
+		name    string
+		t       reflect.Type
+		conv    func(string) (interface{}, error)
+		wantErr bool
+	}{
+		{"nil type", nil, conv, true},
+		{"nil converter", reflect.TypeOf(""), nil, true},
+		{"valid", reflect.TypeOf(""), conv, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := WithConverter(tt.t, tt.conv)
+			if err := op(&model{}); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] WithConverter() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNL_P_Enums(t *testing.T) {
+	type T struct {
+		Room   string   `nlp:"room,enum=kitchen|bedroom|master bedroom|garage,synonyms=cuisine:kitchen|bed room:bedroom"`
+		Rooms  []string `nlp:"rooms,enum=kitchen|garage"`
+		Level  int
+		Device string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"clean the {room}",
+		"clean {rooms}",
+		"level {Level}",
+		"turn on {Device}",
+		"turn on the {room} lights",
+	}, WithEnum("Level", []string{"1", "2", "3"}, map[string]string{"low": "1", "high": "3"})))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantErr    error
+	}{
+		0:  {"value", "clean the kitchen", &T{Room: "kitchen"}, nil},
+		1:  {"case", "clean the Garage", &T{Room: "garage"}, nil},
+		2:  {"synonym", "clean the bed room", &T{Room: "bedroom"}, nil},
+		3:  {"trimmed", "clean the big garage please", &T{Room: "garage"}, nil},
+		4:  {"longest", "clean the master bedroom now", &T{Room: "master bedroom"}, nil},
+		5:  {"rejected", "clean the attic", &T{}, ErrNotAllowed},
+		6:  {"slice", "clean kitchen and garage", &T{Rooms: []string{"kitchen", "garage"}}, nil},
+		7:  {"option", "level high", &T{Level: 3}, nil},
+		8:  {"option rejected", "level 4", &T{}, ErrNotAllowed},
+		9:  {"valid sample preferred", "turn on the garage lights", &T{Room: "garage"}, nil},
+		10: {"valid sample", "turn on the radio", &T{Device: "the radio"}, nil},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("test#%d: got err %v want %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithEnum(t *testing.T) {
+	type T struct {
+		Room string `nlp:"room"`
+		Age  int
+	}
+	tests := []struct {
+		name     string
+		keyword  string
+		values   []string
+		synonyms map[string]string
+		wantErr  bool
+	}{
+		{"empty", "room", nil, nil, true},
+		{"blank", "room", []string{"kitchen", " "}, nil, true},
+		{"unknown synonym value", "room", []string{"kitchen"}, map[string]string{"cuisine": "garage"}, true},
+		{"unknown field", "Room", []string{"kitchen"}, nil, true},
+		{"invalid value", "Age", []string{"old"}, nil, true},
+		{"valid", "room", []string{"kitchen"}, map[string]string{"cuisine": "kitchen"}, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newModel(T{}, []string{""}, WithEnum(tt.keyword, tt.values, tt.synonyms))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("[%d] WithEnum() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithTimeFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		formats []string
+		wantErr bool
+	}{
+		{"empty", nil, true},
+		{"blank format", []string{"2006", " "}, true},
+		{"valid formats", []string{"01-02-2006", "2006-01-02"}, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := WithTimeFormats(tt.formats...)
+			if err := op(&model{}); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] WithTimeFormats() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_TimeFormats(t *testing.T) {
+	type T struct {
+		Date time.Time
+		Time time.Time `nlp:"time,layout=15:04|3pm"`
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"on {Date}",
+		"at {time}",
+	}, WithTimeFormats("01-02-2006", "2006-01-02", "02/01/2006"), WithTimeLocation(time.UTC)))
+	failTest(t, nl.Learn())
+
+	date := time.Date(1999, 5, 18, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantLayout string
+		wantErr    bool
+	}{
+		0: {"first", "on 05-18-1999", &T{Date: date}, "01-02-2006", false},
+		1: {"second", "on 1999-05-18", &T{Date: date}, "2006-01-02", false},
+		2: {"third", "on 18/05/1999", &T{Date: date}, "02/01/2006", false},
+		3: {"none", "on 18.05.1999", &T{}, "", true},
+		4: {"field", "at 6pm", &T{Time: time.Date(0, 1, 1, 18, 0, 0, 0, time.UTC)}, "3pm", false},
+		5: {"field model format", "at 05-18-1999", &T{}, "", true},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: got err %v wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil || len(r.Captures) != 1 {
+				t.Fatalf("test#%d: got %+v want 1 capture", i, r)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+			if r.Captures[0].Layout != tt.wantLayout {
+				t.Errorf("test#%d: got layout %q want %q", i, r.Captures[0].Layout, tt.wantLayout)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_SpacedTimeFormats(t *testing.T) {
+	type T struct {
+		Born time.Time
+		Day  time.Time `nlp:"day,layout=Jan 2, 2006"`
+		Name string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"born {Born}",
+		"on {day}",
+		"call {Name} now",
+	}, WithTimeFormats("Jan 2 2006 3:04 PM", "Jan _2 2006"), WithTimeLocation(time.UTC)))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantValue  string
+	}{
+		0: {"layout", "born May 18 1999 6:42 PM", &T{Born: time.Date(1999, 5, 18, 18, 42, 0, 0, time.UTC)}, "May 18 1999 6:42 PM"},
+		1: {"original spacing", "born May  8 1999", &T{Born: time.Date(1999, 5, 8, 0, 0, 0, 0, time.UTC)}, "May  8 1999"},
+		2: {"tag layout", "on May 18, 1999", &T{Day: time.Date(1999, 5, 18, 0, 0, 0, 0, time.UTC)}, "May 18, 1999"},
+		3: {"string", "call John \t Doe", &T{Name: "John \t Doe"}, "John \t Doe"},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("test#%d: NL.Parse() error = %v", i, err)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+			if len(r.Captures) != 1 || r.Captures[0].Value != tt.wantValue {
+				t.Errorf("test#%d: got captures %+v want value %q", i, r.Captures, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_Hints(t *testing.T) {
+	type T struct {
+		ReleasedAt time.Time
+		Count      int
+		Zip        string
+		Code       string
+		Amount     string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"released in {ReleasedAt:Jan 2006}",
+		"tickets {Count:int}",
+		"deliver to {Zip:/[0-9]{5}/}",
+		"open door {Code:/[a-z]+\\/[0-9]+/}",
+		"pay {Amount:float}",
+	}))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantErr    bool
+	}{
+		0: {"layout", "released in May 1999", &T{ReleasedAt: time.Date(1999, 5, 1, 0, 0, 0, 0, time.Local)}, false},
+		1: {"layout rejected", "released in 1999", &T{}, true},
+		2: {"type", "tickets 3", &T{Count: 3}, false},
+		3: {"regexp", "deliver to zip 90210 please", &T{Zip: "90210"}, false},
+		4: {"regexp rejected", "deliver to home", &T{}, true},
+		5: {"escaped slash", "open door a/42", &T{Code: "a/42"}, false},
+		6: {"string type", "pay 3.50", &T{Amount: "3.50"}, false},
+		7: {"string type rejected", "pay many", &T{}, true},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: NL.Parse() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_Learn_Hints(t *testing.T) {
+	type T struct {
+		Name  string
+		Count int
+		Ratio float64
+		When  time.Time
+	}
+	tests := []struct {
+		name    string
+		sample  string
+		wantErr bool
+	}{
+		0: {"time layout", "at {When:15:04}", false},
+		1: {"type", "count {Count:int}", false},
+		2: {"string type", "name {Name:duration}", false},
+		3: {"regexp", "name {Name:/[a-z]+/}", false},
+		4: {"mismatched type", "count {Count:float}", true},
+		5: {"layout in non-time field", "ratio {Ratio:0.00}", true},
+		6: {"invalid regexp", "name {Name:/[a-z/}", true},
+		7: {"time type", "at {When:time}", false},
+		8: {"mismatched time type", "at {When:int}", true},
+		9: {"hint in optional group", "name {Name} [at {When:15:04}]", false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nl := New()
+			failTest(t, nl.RegisterModel(T{}, []string{tt.sample}))
+			if err := nl.Learn(); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] NL.Learn() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_Optionals(t *testing.T) {
+	type Song struct {
+		Name       string
+		Artist     string
+		ReleasedAt time.Time
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Song{}, []string{
+		"play {Name} [by {Artist}] [from {ReleasedAt}]",
+	}, WithTimeFormat("2006"), WithTimeLocation(time.UTC)))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name          string
+		expression    string
+		want          *Song
+		wantOptionals []bool
+	}{
+		0: {"none", "play King", &Song{Name: "King"}, []bool{false, false}},
+		1: {"first", "play King by Lauren Aquilina", &Song{Name: "King", Artist: "Lauren Aquilina"}, []bool{true, false}},
+		2: {"second", "play King from 2013", &Song{Name: "King", ReleasedAt: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)}, []bool{false, true}},
+		3: {"both", "play King by Lauren Aquilina from 2013", &Song{Name: "King", Artist: "Lauren Aquilina", ReleasedAt: time.Date(2013, 1, 1, 0, 0, 0, 0, time.UTC)}, []bool{true, true}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("test#%d: NL.Parse() error = %v", i, err)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+			if !reflect.DeepEqual(r.Optionals, tt.wantOptionals) {
+				t.Errorf("test#%d: got optionals %v want %v", i, r.Optionals, tt.wantOptionals)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_Alternations(t *testing.T) {
+	type Song struct {
+		Name   string
+		Artist string
+	}
+	type Weather struct {
+		City string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Song{}, []string{
+		"(play|put on|queue|start) {Name} [(by|from) {Artist}]",
+	}))
+	failTest(t, nl.RegisterModel(Weather{}, []string{"weather in {City}"}))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *Song
+	}{
+		0: {"first", "play King", &Song{Name: "King"}},
+		1: {"several words", "put on King by Lauren Aquilina", &Song{Name: "King", Artist: "Lauren Aquilina"}},
+		2: {"optional", "please queue King from Lauren Aquilina", &Song{Name: "King", Artist: "Lauren Aquilina"}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("test#%d: NL.Parse() error = %v", i, err)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+
+	// the alternatives are learned as a single sample, so they
+	// don't make the song model more likely than the weather one
+	for _, r := range nl.PTopN("hello there", 2) {
+		if math.Abs(r.Probability-0.5) > 1e-9 {
+			t.Errorf("model#%d: got probability %v want 0.5", r.Model, r.Probability)
+		}
+	}
+}
+
+func TestNL_Parse_Braces(t *testing.T) {
+	type Snippet struct {
+		Code string
+		Lang string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Snippet{}, []string{
+		"run {Code} in {Lang}",
+		`json \{ {Code}`,
+	}))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *Snippet
+	}{
+		0: {"code", "run func() { return } in go", &Snippet{Code: "func() { return }", Lang: "go"}},
+		1: {"unbalanced", "run {{ in go", &Snippet{Code: "{{", Lang: "go"}},
+		2: {"escaped limit", "json { a: 1", &Snippet{Code: "a: 1"}},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("test#%d: NL.Parse() error = %v", i, err)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_AdjacentKeywords(t *testing.T) {
+	type Transfer struct {
+		Amount   float64
+		Currency string `nlp:"Currency,enum=usd|eur|us dollars,synonyms=euros:eur"`
+		Name     string
+	}
+	type Timer struct {
+		Hours   int
+		Minutes int
+	}
+	type Person struct {
+		First string
+		Last  string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Transfer{}, []string{"transfer {Amount} {Currency} to {Name}"}))
+	failTest(t, nl.RegisterModel(Timer{}, []string{"countdown of {Hours} {Minutes}"}))
+	failTest(t, nl.Learn())
+	// Person shares the keyword name with Transfer, so it's learned
+	// alone to test the split instead of the classifier
+	people := New()
+	failTest(t, people.RegisterModel(Person{}, []string{"my name is {First} {Last}"}))
+	failTest(t, people.Learn())
+
+	cases := []struct {
+		name       string
+		nl         *NL
+		expression string
+		want       interface{}
+		wantErr    bool
+	}{
+		0: {"number and enum", nl, "transfer 50 usd to bob", &Transfer{Amount: 50, Currency: "usd", Name: "bob"}, false},
+		1: {"several words", nl, "transfer 1,200.5 us dollars to bob", &Transfer{Amount: 1200.5, Currency: "us dollars", Name: "bob"}, false},
+		2: {"number words", nl, "transfer forty two euros to bob", &Transfer{Amount: 42, Currency: "eur", Name: "bob"}, false},
+		3: {"missing value", nl, "transfer 50 to bob", &Transfer{Amount: 50, Name: "bob"}, false},
+		4: {"invalid value", nl, "transfer 50 bitcoin to bob", &Transfer{Amount: 50, Name: "bob"}, true},
+		5: {"numbers", nl, "countdown of twenty five ten", &Timer{Hours: 25, Minutes: 10}, false},
+		6: {"strings", people, "my name is john ronald tolkien", &Person{First: "john ronald", Last: "tolkien"}, false},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := tt.nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: NL.Parse() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
+
+func TestNL_Parse_AdjacentKeywordsLongExpression(t *testing.T) {
+	type Log struct {
+		Level   string `nlp:"Level,enum=info|warn|error"`
+		Count   int
+		Message string
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(Log{}, []string{"log {Level} {Count} {Message}"}))
+	failTest(t, nl.Learn())
+
+	message := strings.TrimSpace(strings.Repeat("disk almost full ", 100))
+	r, err := nl.Parse("log warn 3 " + message)
+	failTest(t, err)
+	want := &Log{Level: "warn", Count: 3, Message: message}
+	if !reflect.DeepEqual(r.Value, want) {
+		t.Errorf("got %+v want %+v", r.Value, want)
+	}
+}
@@ -0,0 +1,272 @@
+package nlp
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NumberWords are the words used to write numbers in a language,
+// numeric fields accept the numbers written with them:
+//
+//	"forty two", "a dozen", "three point five", "1,200", "2 million"
+type NumberWords struct {
+	// Numbers are the words added to the number being read, like
+	// "forty": 40 and "two": 2 in "forty two"
+	Numbers map[string]float64
+	// Scales are the words that multiply the number before them,
+	// like "hundred": 100 or "dozen": 12, the scales from 1000 on
+	// end a group of digits, like "thousand" in "two thousand five"
+	Scales map[string]float64
+	// Articles are the words that mean one before a scale, like "a"
+	// in "a dozen", or on their own
+	Articles []string
+	// Conjunctions are the words ignored between numbers, like "and"
+	Conjunctions []string
+	// Negative are the words that negate the number after them
+	Negative []string
+	// Point is the word before the decimal digits, like "point"
+	// in "three point one four"
+	Point string
+	// Group and Decimal are the digit grouping and decimal separators
+	// of numbers written with digits, like "," and "." in "1,200.5"
+	Group, Decimal string
+}
+
+// EnglishNumbers are the english NumberWords, the default of every model
+var EnglishNumbers = NumberWords{
+	Numbers: map[string]float64{
+		"zero": 0, "one": 1, "two": 2, "three": 3, "four": 4,
+		"five": 5, "six": 6, "seven": 7, "eight": 8, "nine": 9,
+		"ten": 10, "eleven": 11, "twelve": 12, "thirteen": 13, "fourteen": 14,
+		"fifteen": 15, "sixteen": 16, "seventeen": 17, "eighteen": 18, "nineteen": 19,
+		"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50,
+		"sixty": 60, "seventy": 70, "eighty": 80, "ninety": 90,
+		"half": 0.5,
+	},
+	Scales: map[string]float64{
+		"dozen":    12,
+		"hundred":  100,
+		"thousand": 1e3,
+		"million":  1e6,
+		"billion":  1e9,
+		"trillion": 1e12,
+	},
+	Articles:     []string{"a", "an"},
+	Conjunctions: []string{"and"},
+	Negative:     []string{"minus", "negative"},
+	Point:        "point",
+	Group:        ",",
+	Decimal:      ".",
+}
+
+// WithNumberWords sets the words of the numbers accepted by numeric
+// fields, they're tried in order, the default is EnglishNumbers
+func WithNumberWords(words ...NumberWords) ModelOption {
+	return func(m *model) error {
+		if len(words) == 0 {
+			return errors.New("number words can't be empty")
+		}
+		for _, nw := range words {
+			if nw.Decimal != "" && nw.Decimal == nw.Group {
+				return errors.New("number words can't have the same group and decimal separator")
+			}
+		}
+		m.numbers = words
+		return nil
+	}
+}
+
+// numeral returns s written with digits if it's a number written with the
+// number words of the model, otherwise s is returned, so strconv can read it.
+// Integers are written exactly, so the limits of int64 and uint64 or
+// integers above 2^53 aren't rounded by a float64
+func (m *model) numeral(s string) string {
+	for _, nw := range m.numbers {
+		// a lone article means one in "an hour", but not in a field
+		if contains(nw.Articles, strings.ToLower(strings.TrimSpace(s))) {
+			continue
+		}
+		if v, ok := nw.exact(s); ok {
+			if v.IsInt() {
+				return v.Num().String()
+			}
+			f, _ := v.Float64()
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return s
+}
+
+// parseNumber parses a number written with the EnglishNumbers
+func parseNumber(s string) (float64, bool) {
+	return EnglishNumbers.parse(s)
+}
+
+// parse parses a number written with digits, with words or both
+func (nw NumberWords) parse(s string) (float64, bool) {
+	v, ok := nw.exact(s)
+	if !ok {
+		return 0, false
+	}
+	f, _ := v.Float64()
+	return f, true
+}
+
+// exact parses a number written with digits, with words or both
+// without losing precision
+func (nw NumberWords) exact(s string) (*big.Rat, bool) {
+	var words []string
+	for _, w := range strings.Fields(strings.ToLower(s)) {
+		// forty-two
+		if strings.IndexFunc(w, unicode.IsLetter) != -1 {
+			words = append(words, strings.FieldsFunc(w, func(r rune) bool { return r == '-' })...)
+			continue
+		}
+		words = append(words, w)
+	}
+	if len(words) == 0 {
+		return nil, false
+	}
+	negative := false
+	if contains(nw.Negative, words[0]) {
+		negative, words = true, words[1:]
+	}
+	if len(words) == 0 {
+		return nil, false
+	}
+	total, current := new(big.Rat), new(big.Rat)
+	// last is the last number added to current
+	var last float64
+	var hasLast bool
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if v, ok := nw.digits(w); ok {
+			if hasLast {
+				return nil, false
+			}
+			current.Add(current, v)
+			last, _ = v.Float64()
+			hasLast = true
+			continue
+		}
+		if v, ok := nw.Numbers[w]; ok {
+			// only "forty two" like numbers are added to the last number
+			if hasLast && !(last >= 20 && last < 100 && math.Mod(last, 10) == 0 && v < 10) && v != 0.5 {
+				return nil, false
+			}
+			r := new(big.Rat).SetFloat64(v)
+			if r == nil {
+				return nil, false
+			}
+			current.Add(current, r)
+			last, hasLast = v, true
+			continue
+		}
+		if v, ok := nw.Scales[w]; ok {
+			r := new(big.Rat).SetFloat64(v)
+			if r == nil {
+				return nil, false
+			}
+			if current.Sign() == 0 {
+				current.SetInt64(1)
+			}
+			current.Mul(current, r)
+			if v >= 1000 {
+				total.Add(total, current)
+				current = new(big.Rat)
+			}
+			hasLast = false
+			continue
+		}
+		if w == nw.Point && nw.Point != "" {
+			frac, ok := nw.fraction(words[i+1:])
+			if !ok {
+				return nil, false
+			}
+			current.Add(current, frac)
+			break
+		}
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		switch {
+		case contains(nw.Articles, w) && len(words) == 1:
+			current.Add(current, big.NewRat(1, 1))
+		case contains(nw.Articles, w) && (nw.Scales[next] != 0 || nw.Numbers[next] != 0):
+			// a dozen, a half
+		case contains(nw.Conjunctions, w) && i > 0 && next != "":
+			// one hundred and five
+		default:
+			return nil, false
+		}
+	}
+	total.Add(total, current)
+	if negative {
+		total.Neg(total)
+	}
+	return total, true
+}
+
+// fraction returns the decimal digits written in words, like
+// "one four" in "three point one four"
+func (nw NumberWords) fraction(words []string) (*big.Rat, bool) {
+	if len(words) == 0 {
+		return nil, false
+	}
+	var digits strings.Builder
+	digits.WriteString("0.")
+	for _, w := range words {
+		if v, ok := nw.Numbers[w]; ok && v >= 0 && v <= 9 && v == math.Trunc(v) {
+			digits.WriteByte(byte('0' + v))
+			continue
+		}
+		if strings.Trim(w, "0123456789") != "" {
+			return nil, false
+		}
+		digits.WriteString(w)
+	}
+	return new(big.Rat).SetString(digits.String())
+}
+
+// digits parses a number written with digits and the
+// separators of nw, like 1,200 or 1,200.5 in english
+func (nw NumberWords) digits(w string) (*big.Rat, bool) {
+	intPart, frac, hasFrac := w, "", false
+	if nw.Decimal != "" {
+		intPart, frac, hasFrac = strings.Cut(w, nw.Decimal)
+	}
+	if nw.Group != "" && strings.Contains(intPart, nw.Group) {
+		groups := strings.Split(strings.TrimPrefix(intPart, "-"), nw.Group)
+		if len(groups[0]) == 0 || len(groups[0]) > 3 {
+			return nil, false
+		}
+		for _, g := range groups[1:] {
+			if len(g) != 3 {
+				return nil, false
+			}
+		}
+		intPart = strings.ReplaceAll(intPart, nw.Group, "")
+	}
+	if strings.Trim(strings.TrimPrefix(intPart, "-"), "0123456789") != "" || strings.Trim(frac, "0123456789") != "" {
+		return nil, false
+	}
+	s := intPart
+	if hasFrac {
+		s += "." + frac
+	}
+	return new(big.Rat).SetString(s)
+}
+
+func contains(words []string, w string) bool {
+	for _, v := range words {
+		if v == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package nlp
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestNumberWords_parse(t *testing.T) {
+	tests := []struct {
+		expr   string
+		want   float64
+		wantOk bool
+	}{
+		{"42", 42, true},
+		{"1.5", 1.5, true},
+		{"forty two", 42, true},
+		{"Forty-Two", 42, true},
+		{"a dozen", 12, true},
+		{"two dozen", 24, true},
+		{"half a dozen", 6, true},
+		{"three point five", 3.5, true},
+		{"three point one four", 3.14, true},
+		{"1,200", 1200, true},
+		{"1,200,000.5", 1200000.5, true},
+		{"2 million", 2e6, true},
+		{"2.5 million", 2.5e6, true},
+		{"a hundred", 100, true},
+		{"one hundred and five", 105, true},
+		{"two thousand and twenty", 2020, true},
+		{"one million two hundred thousand", 1.2e6, true},
+		{"two and a half", 2.5, true},
+		{"minus five", -5, true},
+		{"an", 1, true},
+		{"", 0, false},
+		{"many", 0, false},
+		{"five four", 0, false},
+		{"twenty forty", 0, false},
+		{"2 3", 0, false},
+		{"1,20", 0, false},
+		{"1200,000", 0, false},
+		{"three point", 0, false},
+		{"three point twelve", 0, false},
+		{"and", 0, false},
+		{"one and", 0, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, ok := EnglishNumbers.parse(tt.expr)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("[%d] NumberWords.parse() = %v, %v want %v, %v", i, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestWithNumberWords(t *testing.T) {
+	type T struct {
+		Int   int
+		Float float64
+		Uint  uint8
+	}
+
+	spanish := NumberWords{
+		Numbers:      map[string]float64{"uno": 1, "dos": 2, "tres": 3, "veinte": 20, "cinco": 5},
+		Scales:       map[string]float64{"docena": 12, "mil": 1e3},
+		Articles:     []string{"una"},
+		Conjunctions: []string{"y"},
+		Point:        "coma",
+		Group:        ".",
+		Decimal:      ",",
+	}
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"int {Int}",
+		"float {Float}",
+		"uint {Uint}",
+	}, WithNumberWords(spanish, EnglishNumbers)))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		name       string
+		expression string
+		want       *T
+		wantErr    bool
+	}{
+		0:  {"words", "int veinte y dos", &T{Int: 22}, false},
+		1:  {"scale", "int una docena", &T{Int: 12}, false},
+		2:  {"grouping", "int 1.200", &T{Int: 1200}, false},
+		3:  {"point", "float tres coma cinco", &T{Float: 3.5}, false},
+		4:  {"decimal", "float 3,5", &T{Float: 3.5}, false},
+		5:  {"second language", "int forty two", &T{Int: 42}, false},
+		6:  {"not an integer", "int two and a half", &T{}, true},
+		7:  {"overflow", "uint three hundred", &T{}, true},
+		8:  {"unknown", "int cuarenta", &T{}, true},
+		9:  {"article", "int una", &T{}, true},
+		10: {"second language article", "float an", &T{}, true},
+	}
+	for i, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: got err %v wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+
+	if err := WithNumberWords()(&model{}); err == nil {
+		t.Error("WithNumberWords() without words should fail")
+	}
+	if err := WithNumberWords(NumberWords{Group: ".", Decimal: "."})(&model{}); err == nil {
+		t.Error("WithNumberWords() with the same separators should fail")
+	}
+}
+
+func TestNumberLimits(t *testing.T) {
+	type T struct {
+		Int   int64
+		Uint  uint64
+		Float float64
+	}
+
+	nl := New()
+	failTest(t, nl.RegisterModel(T{}, []string{
+		"int {Int}",
+		"uint {Uint}",
+		"float {Float}",
+	}))
+	failTest(t, nl.Learn())
+
+	cases := []struct {
+		expression string
+		want       *T
+		wantErr    bool
+	}{
+		0:  {"int 9223372036854775807", &T{Int: math.MaxInt64}, false},
+		1:  {"int -9223372036854775808", &T{Int: math.MinInt64}, false},
+		2:  {"int 9,223,372,036,854,775,807", &T{Int: math.MaxInt64}, false},
+		3:  {"int 9223372036854775808", &T{}, true},
+		4:  {"int 9007199254740993", &T{Int: 9007199254740993}, false},
+		5:  {"uint 18446744073709551615", &T{Uint: math.MaxUint64}, false},
+		6:  {"uint 18446744073709551616", &T{}, true},
+		7:  {"uint 18,446,744,073,709,551,615", &T{Uint: math.MaxUint64}, false},
+		8:  {"int 9,007,199,254,740 thousand nine hundred ninety three", &T{Int: 9007199254740993}, false},
+		9:  {"uint 18446744073 billion", &T{Uint: 18446744073000000000}, false},
+		10: {"float 1.5", &T{Float: 1.5}, false},
+	}
+	for i, tt := range cases {
+		t.Run(tt.expression, func(t *testing.T) {
+			r, err := nl.Parse(tt.expression)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("test#%d: got err %v wantErr %v", i, err, tt.wantErr)
+			}
+			if r == nil {
+				t.Fatalf("test#%d: got nil result", i)
+			}
+			if !reflect.DeepEqual(r.Value, tt.want) {
+				t.Errorf("test#%d: got %+v want %+v", i, r.Value, tt.want)
+			}
+		})
+	}
+}
@@ -19,6 +19,15 @@ import (
 type Token struct {
 	Kw  bool
 	Val []byte
+	// Hint is the text after the colon of a keyword,
+	// like 2006 in {ReleasedAt:2006} or /[0-9]+/ in {Zip:/[0-9]+/}
+	Hint []byte
+	// Optional is the number of the optional group the token is in,
+	// like [by {Artist}], starting at 1, or 0 if it isn't optional
+	Optional int
+	// Alternatives are the alternatives of an alternation like
+	// (play|put on), with their words separated by a space
+	Alternatives [][]byte
 }
 
 // ParseSample will return the tokens within the sample
@@ -37,203 +46,450 @@ func ParseSample(sampleID int, sample []byte) ([]Token, error) {
 	return tokens.([]Token), nil
 }
 
+// unescape removes the backslashes of the escaped characters of b,
+// like \{ in a sample, b is returned if it doesn't have any
+func unescape(b []byte) []byte {
+	if bytes.IndexByte(b, '\\') == -1 {
+		return b
+	}
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] == '\\' && i+1 < len(b) && bytes.IndexByte([]byte("{}[]()|\\"), b[i+1]) != -1 {
+			i++
+		}
+		out = append(out, b[i])
+	}
+	return out
+}
+
 var g = &grammar{
 	rules: []*rule{
 		{
 			name:        "Sample",
 			displayName: "\"sample\"",
-			pos:         position{line: 32, col: 1, offset: 685},
+			pos:         position{line: 58, col: 1, offset: 1614},
 			expr: &actionExpr{
-				pos: position{line: 33, col: 3, offset: 703},
+				pos: position{line: 59, col: 3, offset: 1632},
 				run: (*parser).callonSample1,
-				expr: &labeledExpr{
-					pos:   position{line: 33, col: 3, offset: 703},
-					label: "vs",
-					expr: &zeroOrMoreExpr{
-						pos: position{line: 33, col: 6, offset: 706},
-						expr: &choiceExpr{
-							pos: position{line: 33, col: 7, offset: 707},
-							alternatives: []interface{}{
-								&ruleRefExpr{
-									pos:  position{line: 33, col: 7, offset: 707},
-									name: "Identifier",
-								},
-								&ruleRefExpr{
-									pos:  position{line: 33, col: 20, offset: 720},
-									name: "Keyword",
-								},
-								&ruleRefExpr{
-									pos:  position{line: 33, col: 30, offset: 730},
-									name: "Spacing",
+				expr: &seqExpr{
+					pos: position{line: 59, col: 3, offset: 1632},
+					exprs: []interface{}{
+						&labeledExpr{
+							pos:   position{line: 59, col: 3, offset: 1632},
+							label: "vs",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 59, col: 6, offset: 1635},
+								expr: &choiceExpr{
+									pos: position{line: 59, col: 7, offset: 1636},
+									alternatives: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 59, col: 7, offset: 1636},
+											name: "Optional",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 59, col: 18, offset: 1647},
+											name: "Alternation",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 59, col: 32, offset: 1661},
+											name: "Identifier",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 59, col: 45, offset: 1674},
+											name: "Keyword",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 59, col: 55, offset: 1684},
+											name: "Spacing",
+										},
+									},
 								},
 							},
 						},
+						&ruleRefExpr{
+							pos:  position{line: 59, col: 65, offset: 1694},
+							name: "EOF",
+						},
 					},
 				},
 			},
 		},
 		{
-			name:        "Keyword",
-			displayName: "\"keyword\"",
-			pos:         position{line: 48, col: 1, offset: 1050},
-			expr: &choiceExpr{
-				pos: position{line: 49, col: 3, offset: 1070},
-				alternatives: []interface{}{
-					&actionExpr{
-						pos: position{line: 49, col: 3, offset: 1070},
-						run: (*parser).callonKeyword2,
-						expr: &seqExpr{
-							pos: position{line: 49, col: 3, offset: 1070},
-							exprs: []interface{}{
-								&litMatcher{
-									pos:        position{line: 49, col: 3, offset: 1070},
-									val:        "{",
-									ignoreCase: false,
-								},
-								&oneOrMoreExpr{
-									pos: position{line: 49, col: 7, offset: 1074},
-									expr: &ruleRefExpr{
-										pos:  position{line: 49, col: 7, offset: 1074},
-										name: "Spacing",
-									},
-								},
-								&labeledExpr{
-									pos:   position{line: 49, col: 16, offset: 1083},
-									label: "v",
-									expr: &ruleRefExpr{
-										pos:  position{line: 49, col: 18, offset: 1085},
-										name: "Identifier",
+			name:        "Optional",
+			displayName: "\"optional\"",
+			pos:         position{line: 85, col: 1, offset: 2299},
+			expr: &actionExpr{
+				pos: position{line: 86, col: 3, offset: 2321},
+				run: (*parser).callonOptional1,
+				expr: &seqExpr{
+					pos: position{line: 86, col: 3, offset: 2321},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 86, col: 3, offset: 2321},
+							val:        "[",
+							ignoreCase: false,
+						},
+						&labeledExpr{
+							pos:   position{line: 86, col: 7, offset: 2325},
+							label: "vs",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 86, col: 10, offset: 2328},
+								expr: &choiceExpr{
+									pos: position{line: 86, col: 11, offset: 2329},
+									alternatives: []interface{}{
+										&ruleRefExpr{
+											pos:  position{line: 86, col: 11, offset: 2329},
+											name: "Alternation",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 86, col: 25, offset: 2343},
+											name: "Identifier",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 86, col: 38, offset: 2356},
+											name: "Keyword",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 86, col: 48, offset: 2366},
+											name: "Spacing",
+										},
 									},
 								},
-								&litMatcher{
-									pos:        position{line: 49, col: 29, offset: 1096},
-									val:        "}",
-									ignoreCase: false,
-								},
 							},
 						},
+						&litMatcher{
+							pos:        position{line: 86, col: 58, offset: 2376},
+							val:        "]",
+							ignoreCase: false,
+						},
 					},
-					&actionExpr{
-						pos: position{line: 52, col: 3, offset: 1158},
-						run: (*parser).callonKeyword10,
-						expr: &seqExpr{
-							pos: position{line: 52, col: 3, offset: 1158},
-							exprs: []interface{}{
-								&litMatcher{
-									pos:        position{line: 52, col: 3, offset: 1158},
-									val:        "{",
-									ignoreCase: false,
-								},
-								&labeledExpr{
-									pos:   position{line: 52, col: 7, offset: 1162},
-									label: "v",
-									expr: &ruleRefExpr{
-										pos:  position{line: 52, col: 9, offset: 1164},
-										name: "Identifier",
-									},
-								},
-								&oneOrMoreExpr{
-									pos: position{line: 52, col: 20, offset: 1175},
-									expr: &ruleRefExpr{
-										pos:  position{line: 52, col: 20, offset: 1175},
-										name: "Spacing",
+				},
+			},
+		},
+		{
+			name:        "Alternation",
+			displayName: "\"alternation\"",
+			pos:         position{line: 98, col: 1, offset: 2599},
+			expr: &actionExpr{
+				pos: position{line: 99, col: 3, offset: 2627},
+				run: (*parser).callonAlternation1,
+				expr: &seqExpr{
+					pos: position{line: 99, col: 3, offset: 2627},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 99, col: 3, offset: 2627},
+							val:        "(",
+							ignoreCase: false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 99, col: 7, offset: 2631},
+							expr: &ruleRefExpr{
+								pos:  position{line: 99, col: 7, offset: 2631},
+								name: "Spacing",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 99, col: 16, offset: 2640},
+							label: "a",
+							expr: &ruleRefExpr{
+								pos:  position{line: 99, col: 18, offset: 2642},
+								name: "Alternative",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 99, col: 30, offset: 2654},
+							label: "as",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 99, col: 33, offset: 2657},
+								expr: &seqExpr{
+									pos: position{line: 99, col: 34, offset: 2658},
+									exprs: []interface{}{
+										&zeroOrMoreExpr{
+											pos: position{line: 99, col: 34, offset: 2658},
+											expr: &ruleRefExpr{
+												pos:  position{line: 99, col: 34, offset: 2658},
+												name: "Spacing",
+											},
+										},
+										&litMatcher{
+											pos:        position{line: 99, col: 43, offset: 2667},
+											val:        "|",
+											ignoreCase: false,
+										},
+										&zeroOrMoreExpr{
+											pos: position{line: 99, col: 47, offset: 2671},
+											expr: &ruleRefExpr{
+												pos:  position{line: 99, col: 47, offset: 2671},
+												name: "Spacing",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 99, col: 56, offset: 2680},
+											name: "Alternative",
+										},
 									},
 								},
-								&litMatcher{
-									pos:        position{line: 52, col: 29, offset: 1184},
-									val:        "}",
-									ignoreCase: false,
-								},
 							},
 						},
+						&zeroOrMoreExpr{
+							pos: position{line: 99, col: 70, offset: 2694},
+							expr: &ruleRefExpr{
+								pos:  position{line: 99, col: 70, offset: 2694},
+								name: "Spacing",
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 99, col: 79, offset: 2703},
+							val:        ")",
+							ignoreCase: false,
+						},
 					},
-					&actionExpr{
-						pos: position{line: 55, col: 3, offset: 1246},
-						run: (*parser).callonKeyword18,
-						expr: &seqExpr{
-							pos: position{line: 55, col: 3, offset: 1246},
-							exprs: []interface{}{
-								&litMatcher{
-									pos:        position{line: 55, col: 3, offset: 1246},
-									val:        "{",
-									ignoreCase: false,
-								},
-								&oneOrMoreExpr{
-									pos: position{line: 55, col: 7, offset: 1250},
-									expr: &ruleRefExpr{
-										pos:  position{line: 55, col: 7, offset: 1250},
-										name: "Spacing",
+				},
+			},
+		},
+		{
+			name:        "Alternative",
+			displayName: "\"alternative\"",
+			pos:         position{line: 107, col: 1, offset: 2926},
+			expr: &actionExpr{
+				pos: position{line: 108, col: 3, offset: 2954},
+				run: (*parser).callonAlternative1,
+				expr: &seqExpr{
+					pos: position{line: 108, col: 3, offset: 2954},
+					exprs: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 108, col: 3, offset: 2954},
+							name: "AltWord",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 108, col: 11, offset: 2962},
+							expr: &seqExpr{
+								pos: position{line: 108, col: 12, offset: 2963},
+								exprs: []interface{}{
+									&oneOrMoreExpr{
+										pos: position{line: 108, col: 12, offset: 2963},
+										expr: &ruleRefExpr{
+											pos:  position{line: 108, col: 12, offset: 2963},
+											name: "Space",
+										},
 									},
-								},
-								&labeledExpr{
-									pos:   position{line: 55, col: 16, offset: 1259},
-									label: "v",
-									expr: &ruleRefExpr{
-										pos:  position{line: 55, col: 18, offset: 1261},
-										name: "Identifier",
+									&ruleRefExpr{
+										pos:  position{line: 108, col: 19, offset: 2970},
+										name: "AltWord",
 									},
 								},
-								&oneOrMoreExpr{
-									pos: position{line: 55, col: 29, offset: 1272},
-									expr: &ruleRefExpr{
-										pos:  position{line: 55, col: 29, offset: 1272},
-										name: "Spacing",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "AltWord",
+			displayName: "\"alternative word\"",
+			pos:         position{line: 112, col: 1, offset: 3057},
+			expr: &oneOrMoreExpr{
+				pos: position{line: 113, col: 3, offset: 3086},
+				expr: &choiceExpr{
+					pos: position{line: 113, col: 4, offset: 3087},
+					alternatives: []interface{}{
+						&ruleRefExpr{
+							pos:  position{line: 113, col: 4, offset: 3087},
+							name: "Escape",
+						},
+						&charClassMatcher{
+							pos:        position{line: 113, col: 13, offset: 3096},
+							val:        "[^{}[\\]()| \\t\\r\\n]",
+							chars:      []rune{'{', '}', '[', ']', '(', ')', '|', ' ', '\t', '\r', '\n'},
+							ignoreCase: false,
+							inverted:   true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "Keyword",
+			displayName: "\"keyword\"",
+			pos:         position{line: 115, col: 1, offset: 3118},
+			expr: &actionExpr{
+				pos: position{line: 116, col: 3, offset: 3138},
+				run: (*parser).callonKeyword1,
+				expr: &seqExpr{
+					pos: position{line: 116, col: 3, offset: 3138},
+					exprs: []interface{}{
+						&litMatcher{
+							pos:        position{line: 116, col: 3, offset: 3138},
+							val:        "{",
+							ignoreCase: false,
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 116, col: 7, offset: 3142},
+							expr: &ruleRefExpr{
+								pos:  position{line: 116, col: 7, offset: 3142},
+								name: "Spacing",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 116, col: 16, offset: 3151},
+							label: "v",
+							expr: &ruleRefExpr{
+								pos:  position{line: 116, col: 18, offset: 3153},
+								name: "Name",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 116, col: 23, offset: 3158},
+							label: "h",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 116, col: 25, offset: 3160},
+								expr: &seqExpr{
+									pos: position{line: 116, col: 26, offset: 3161},
+									exprs: []interface{}{
+										&litMatcher{
+											pos:        position{line: 116, col: 26, offset: 3161},
+											val:        ":",
+											ignoreCase: false,
+										},
+										&ruleRefExpr{
+											pos:  position{line: 116, col: 30, offset: 3165},
+											name: "Hint",
+										},
 									},
 								},
-								&litMatcher{
-									pos:        position{line: 55, col: 38, offset: 1281},
-									val:        "}",
-									ignoreCase: false,
-								},
 							},
 						},
+						&zeroOrMoreExpr{
+							pos: position{line: 116, col: 37, offset: 3172},
+							expr: &ruleRefExpr{
+								pos:  position{line: 116, col: 37, offset: 3172},
+								name: "Spacing",
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 116, col: 46, offset: 3181},
+							val:        "}",
+							ignoreCase: false,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:        "Name",
+			displayName: "\"name\"",
+			pos:         position{line: 124, col: 1, offset: 3327},
+			expr: &actionExpr{
+				pos: position{line: 125, col: 3, offset: 3341},
+				run: (*parser).callonName1,
+				expr: &oneOrMoreExpr{
+					pos: position{line: 125, col: 3, offset: 3341},
+					expr: &charClassMatcher{
+						pos:        position{line: 125, col: 3, offset: 3341},
+						val:        "[^{}: \\t\\r\\n]",
+						chars:      []rune{'{', '}', ':', ' ', '\t', '\r', '\n'},
+						ignoreCase: false,
+						inverted:   true,
 					},
+				},
+			},
+		},
+		{
+			name:        "Hint",
+			displayName: "\"hint\"",
+			pos:         position{line: 129, col: 1, offset: 3396},
+			expr: &choiceExpr{
+				pos: position{line: 130, col: 3, offset: 3410},
+				alternatives: []interface{}{
 					&actionExpr{
-						pos: position{line: 58, col: 3, offset: 1343},
-						run: (*parser).callonKeyword28,
+						pos: position{line: 130, col: 3, offset: 3410},
+						run: (*parser).callonHint2,
 						expr: &seqExpr{
-							pos: position{line: 58, col: 3, offset: 1343},
+							pos: position{line: 130, col: 3, offset: 3410},
 							exprs: []interface{}{
 								&litMatcher{
-									pos:        position{line: 58, col: 3, offset: 1343},
-									val:        "{",
+									pos:        position{line: 130, col: 3, offset: 3410},
+									val:        "/",
 									ignoreCase: false,
 								},
-								&labeledExpr{
-									pos:   position{line: 58, col: 7, offset: 1347},
-									label: "v",
-									expr: &ruleRefExpr{
-										pos:  position{line: 58, col: 9, offset: 1349},
-										name: "Identifier",
+								&zeroOrMoreExpr{
+									pos: position{line: 130, col: 7, offset: 3414},
+									expr: &choiceExpr{
+										pos: position{line: 130, col: 8, offset: 3415},
+										alternatives: []interface{}{
+											&seqExpr{
+												pos: position{line: 130, col: 8, offset: 3415},
+												exprs: []interface{}{
+													&litMatcher{
+														pos:        position{line: 130, col: 8, offset: 3415},
+														val:        "\\",
+														ignoreCase: false,
+													},
+													&anyMatcher{
+														line: 130, col: 13, offset: 3420,
+													},
+												},
+											},
+											&charClassMatcher{
+												pos:        position{line: 130, col: 17, offset: 3424},
+												val:        "[^/\\\\]",
+												chars:      []rune{'/', '\\'},
+												ignoreCase: false,
+												inverted:   true,
+											},
+										},
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 58, col: 20, offset: 1360},
-									val:        "}",
+									pos:        position{line: 130, col: 26, offset: 3433},
+									val:        "/",
 									ignoreCase: false,
 								},
 							},
 						},
 					},
+					&actionExpr{
+						pos: position{line: 133, col: 3, offset: 3466},
+						run: (*parser).callonHint12,
+						expr: &oneOrMoreExpr{
+							pos: position{line: 133, col: 3, offset: 3466},
+							expr: &charClassMatcher{
+								pos:        position{line: 133, col: 3, offset: 3466},
+								val:        "[^{}]",
+								chars:      []rune{'{', '}'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+						},
+					},
 				},
 			},
 		},
 		{
 			name:        "Punct",
 			displayName: "\"punct\"",
-			pos:         position{line: 63, col: 1, offset: 1422},
+			pos:         position{line: 138, col: 1, offset: 3519},
 			expr: &actionExpr{
-				pos: position{line: 64, col: 3, offset: 1438},
+				pos: position{line: 139, col: 3, offset: 3535},
 				run: (*parser).callonPunct1,
 				expr: &oneOrMoreExpr{
-					pos: position{line: 64, col: 3, offset: 1438},
-					expr: &charClassMatcher{
-						pos:        position{line: 64, col: 3, offset: 1438},
-						val:        "[^a-zA-Z0-9{} ]",
-						chars:      []rune{'{', '}', ' '},
-						ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
-						ignoreCase: false,
-						inverted:   true,
+					pos: position{line: 139, col: 3, offset: 3535},
+					expr: &choiceExpr{
+						pos: position{line: 139, col: 4, offset: 3536},
+						alternatives: []interface{}{
+							&ruleRefExpr{
+								pos:  position{line: 139, col: 4, offset: 3536},
+								name: "Escape",
+							},
+							&charClassMatcher{
+								pos:        position{line: 139, col: 13, offset: 3545},
+								val:        "[^a-zA-Z0-9{}[\\] \\t\\r\\n]",
+								chars:      []rune{'{', '}', '[', ']', ' ', '\t', '\r', '\n'},
+								ranges:     []rune{'a', 'z', 'A', 'Z', '0', '9'},
+								ignoreCase: false,
+								inverted:   true,
+							},
+						},
 					},
 				},
 			},
@@ -241,49 +497,80 @@ var g = &grammar{
 		{
 			name:        "Identifier",
 			displayName: "\"identifier\"",
-			pos:         position{line: 69, col: 1, offset: 1496},
+			pos:         position{line: 144, col: 1, offset: 3623},
 			expr: &choiceExpr{
-				pos: position{line: 70, col: 3, offset: 1522},
+				pos: position{line: 145, col: 3, offset: 3649},
 				alternatives: []interface{}{
 					&ruleRefExpr{
-						pos:  position{line: 70, col: 3, offset: 1522},
+						pos:  position{line: 145, col: 3, offset: 3649},
 						name: "Punct",
 					},
 					&actionExpr{
-						pos: position{line: 70, col: 11, offset: 1530},
+						pos: position{line: 145, col: 11, offset: 3657},
 						run: (*parser).callonIdentifier3,
 						expr: &oneOrMoreExpr{
-							pos: position{line: 70, col: 11, offset: 1530},
-							expr: &charClassMatcher{
-								pos:        position{line: 70, col: 11, offset: 1530},
-								val:        "[^{} \\t\\r\\n]",
-								chars:      []rune{'{', '}', ' ', '\t', '\r', '\n'},
-								ignoreCase: false,
-								inverted:   true,
+							pos: position{line: 145, col: 11, offset: 3657},
+							expr: &choiceExpr{
+								pos: position{line: 145, col: 12, offset: 3658},
+								alternatives: []interface{}{
+									&ruleRefExpr{
+										pos:  position{line: 145, col: 12, offset: 3658},
+										name: "Escape",
+									},
+									&charClassMatcher{
+										pos:        position{line: 145, col: 21, offset: 3667},
+										val:        "[^{}[\\] \\t\\r\\n]",
+										chars:      []rune{'{', '}', '[', ']', ' ', '\t', '\r', '\n'},
+										ignoreCase: false,
+										inverted:   true,
+									},
+								},
 							},
 						},
 					},
 				},
 			},
 		},
+		{
+			name:        "Escape",
+			displayName: "\"escape\"",
+			pos:         position{line: 149, col: 1, offset: 3735},
+			expr: &seqExpr{
+				pos: position{line: 150, col: 3, offset: 3753},
+				exprs: []interface{}{
+					&litMatcher{
+						pos:        position{line: 150, col: 3, offset: 3753},
+						val:        "\\",
+						ignoreCase: false,
+					},
+					&charClassMatcher{
+						pos:        position{line: 150, col: 8, offset: 3758},
+						val:        "[{}[\\]()|\\\\]",
+						chars:      []rune{'{', '}', '[', ']', '(', ')', '|', '\\'},
+						ignoreCase: false,
+						inverted:   false,
+					},
+				},
+			},
+		},
 		{
 			name:        "Spacing",
 			displayName: "\"spacing\"",
-			pos:         position{line: 74, col: 1, offset: 1584},
+			pos:         position{line: 152, col: 1, offset: 3772},
 			expr: &choiceExpr{
-				pos: position{line: 75, col: 3, offset: 1604},
+				pos: position{line: 153, col: 3, offset: 3792},
 				alternatives: []interface{}{
 					&oneOrMoreExpr{
-						pos: position{line: 75, col: 3, offset: 1604},
+						pos: position{line: 153, col: 3, offset: 3792},
 						expr: &ruleRefExpr{
-							pos:  position{line: 75, col: 3, offset: 1604},
+							pos:  position{line: 153, col: 3, offset: 3792},
 							name: "Space",
 						},
 					},
 					&oneOrMoreExpr{
-						pos: position{line: 75, col: 12, offset: 1613},
+						pos: position{line: 153, col: 12, offset: 3801},
 						expr: &ruleRefExpr{
-							pos:  position{line: 75, col: 12, offset: 1613},
+							pos:  position{line: 153, col: 12, offset: 3801},
 							name: "_",
 						},
 					},
@@ -293,9 +580,9 @@ var g = &grammar{
 		{
 			name:        "Space",
 			displayName: "\"Space\"",
-			pos:         position{line: 77, col: 1, offset: 1617},
+			pos:         position{line: 155, col: 1, offset: 3805},
 			expr: &litMatcher{
-				pos:        position{line: 78, col: 3, offset: 1633},
+				pos:        position{line: 156, col: 3, offset: 3821},
 				val:        " ",
 				ignoreCase: false,
 			},
@@ -303,15 +590,25 @@ var g = &grammar{
 		{
 			name:        "_",
 			displayName: "\"whitespace\"",
-			pos:         position{line: 80, col: 1, offset: 1638},
+			pos:         position{line: 158, col: 1, offset: 3826},
 			expr: &charClassMatcher{
-				pos:        position{line: 81, col: 3, offset: 1655},
+				pos:        position{line: 159, col: 3, offset: 3843},
 				val:        "[\\t\\r\\n]",
 				chars:      []rune{'\t', '\r', '\n'},
 				ignoreCase: false,
 				inverted:   false,
 			},
 		},
+		{
+			name: "EOF",
+			pos:  position{line: 161, col: 1, offset: 3853},
+			expr: &notExpr{
+				pos: position{line: 162, col: 3, offset: 3859},
+				expr: &anyMatcher{
+					line: 162, col: 4, offset: 3860,
+				},
+			},
+		},
 	},
 }
 
@@ -320,10 +617,21 @@ func (c *current) onSample1(vs interface{}) (interface{}, error) {
 		return nil, errors.New("empty sample")
 	}
 	var tokens []Token
+	var optionals int
 	for _, v := range vs.([]interface{}) {
 		switch tk := v.(type) {
 		case Token:
 			tokens = append(tokens, tk)
+		case []Token:
+			if len(tk) == 0 {
+				// [ ]
+				continue
+			}
+			optionals++
+			for _, t := range tk {
+				t.Optional = optionals
+				tokens = append(tokens, t)
+			}
 		default:
 		}
 	}
@@ -336,48 +644,94 @@ func (p *parser) callonSample1() (interface{}, error) {
 	return p.cur.onSample1(stack["vs"])
 }
 
-func (c *current) onKeyword2(v interface{}) (interface{}, error) {
-	return Token{Kw: true, Val: v.(Token).Val}, nil
+func (c *current) onOptional1(vs interface{}) (interface{}, error) {
+	var tokens []Token
+	for _, v := range vs.([]interface{}) {
+		switch tk := v.(type) {
+		case Token:
+			tokens = append(tokens, tk)
+		default:
+		}
+	}
+	return tokens, nil
+}
+
+func (p *parser) callonOptional1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOptional1(stack["vs"])
+}
+
+func (c *current) onAlternation1(a, as interface{}) (interface{}, error) {
+	tk := Token{Val: c.text, Alternatives: [][]byte{a.([]byte)}}
+	for _, v := range as.([]interface{}) {
+		tk.Alternatives = append(tk.Alternatives, v.([]interface{})[3].([]byte))
+	}
+	return tk, nil
+}
+
+func (p *parser) callonAlternation1() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onAlternation1(stack["a"], stack["as"])
+}
+
+func (c *current) onAlternative1() (interface{}, error) {
+	return bytes.Join(bytes.Fields(unescape(c.text)), []byte(" ")), nil
 }
 
-func (p *parser) callonKeyword2() (interface{}, error) {
+func (p *parser) callonAlternative1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onKeyword2(stack["v"])
+	return p.cur.onAlternative1()
 }
 
-func (c *current) onKeyword10(v interface{}) (interface{}, error) {
-	return Token{Kw: true, Val: v.(Token).Val}, nil
+func (c *current) onKeyword1(v, h interface{}) (interface{}, error) {
+	tk := Token{Kw: true, Val: v.(Token).Val}
+	if h != nil {
+		tk.Hint = h.([]interface{})[1].([]byte)
+	}
+	return tk, nil
 }
 
-func (p *parser) callonKeyword10() (interface{}, error) {
+func (p *parser) callonKeyword1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onKeyword10(stack["v"])
+	return p.cur.onKeyword1(stack["v"], stack["h"])
 }
 
-func (c *current) onKeyword18(v interface{}) (interface{}, error) {
-	return Token{Kw: true, Val: v.(Token).Val}, nil
+func (c *current) onName1() (interface{}, error) {
+	return Token{Val: c.text}, nil
 }
 
-func (p *parser) callonKeyword18() (interface{}, error) {
+func (p *parser) callonName1() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onKeyword18(stack["v"])
+	return p.cur.onName1()
 }
 
-func (c *current) onKeyword28(v interface{}) (interface{}, error) {
-	return Token{Kw: true, Val: v.(Token).Val}, nil
+func (c *current) onHint2() (interface{}, error) {
+	return c.text, nil
 }
 
-func (p *parser) callonKeyword28() (interface{}, error) {
+func (p *parser) callonHint2() (interface{}, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onKeyword28(stack["v"])
+	return p.cur.onHint2()
+}
+
+func (c *current) onHint12() (interface{}, error) {
+	return bytes.TrimSpace(c.text), nil
+}
+
+func (p *parser) callonHint12() (interface{}, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onHint12()
 }
 
 func (c *current) onPunct1() (interface{}, error) {
-	return Token{Val: c.text}, nil
+	return Token{Val: unescape(c.text)}, nil
 }
 
 func (p *parser) callonPunct1() (interface{}, error) {
@@ -387,7 +741,7 @@ func (p *parser) callonPunct1() (interface{}, error) {
 }
 
 func (c *current) onIdentifier3() (interface{}, error) {
-	return Token{Val: c.text}, nil
+	return Token{Val: unescape(c.text)}, nil
 }
 
 func (p *parser) callonIdentifier3() (interface{}, error) {
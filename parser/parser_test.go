@@ -56,6 +56,105 @@ func TestParseSample(t *testing.T) {
 			},
 			false,
 		},
+		4: {
+			"layout hint",
+			args{1, []byte("released on {ReleasedAt:2006}")},
+			[]Token{
+				{Val: []byte("released")},
+				{Val: []byte("on")},
+				{Kw: true, Val: []byte("ReleasedAt"), Hint: []byte("2006")},
+			},
+			false,
+		},
+		5: {
+			"spaced hints",
+			args{1, []byte("{Count:int} since { Since: Jan 2 2006 }")},
+			[]Token{
+				{Kw: true, Val: []byte("Count"), Hint: []byte("int")},
+				{Val: []byte("since")},
+				{Kw: true, Val: []byte("Since"), Hint: []byte("Jan 2 2006")},
+			},
+			false,
+		},
+		6: {
+			"regex hint",
+			args{1, []byte(`zip {Zip:/[0-9]{5}/} or {Code:/a\/b/}`)},
+			[]Token{
+				{Val: []byte("zip")},
+				{Kw: true, Val: []byte("Zip"), Hint: []byte("/[0-9]{5}/")},
+				{Val: []byte("or")},
+				{Kw: true, Val: []byte("Code"), Hint: []byte(`/a\/b/`)},
+			},
+			false,
+		},
+		7: {
+			"optional groups",
+			args{1, []byte("play {Name} [by {Artist}] [ from {ReleasedAt} ]")},
+			[]Token{
+				{Val: []byte("play")},
+				{Kw: true, Val: []byte("Name")},
+				{Val: []byte("by"), Optional: 1},
+				{Kw: true, Val: []byte("Artist"), Optional: 1},
+				{Val: []byte("from"), Optional: 2},
+				{Kw: true, Val: []byte("ReleasedAt"), Optional: 2},
+			},
+			false,
+		},
+		8: {
+			"err: unclosed brackets",
+			args{1, []byte("play {Name} [by")},
+			nil,
+			true,
+		},
+		9: {
+			"alternation",
+			args{1, []byte("( play |put   on|queue) {Name} [(by|from) {Artist}]")},
+			[]Token{
+				{Val: []byte("( play |put   on|queue)"), Alternatives: [][]byte{[]byte("play"), []byte("put on"), []byte("queue")}},
+				{Kw: true, Val: []byte("Name")},
+				{Val: []byte("(by|from)"), Alternatives: [][]byte{[]byte("by"), []byte("from")}, Optional: 1},
+				{Kw: true, Val: []byte("Artist"), Optional: 1},
+			},
+			false,
+		},
+		10: {
+			"single alternative",
+			args{1, []byte("(play) {Name}")},
+			[]Token{
+				{Val: []byte("(")},
+				{Val: []byte("play)")},
+				{Kw: true, Val: []byte("Name")},
+			},
+			false,
+		},
+		11: {
+			"escapes",
+			args{1, []byte(`price in \{USD\} a\[1\] \(a\|b\) c:\\ {Amount}`)},
+			[]Token{
+				{Val: []byte("price")},
+				{Val: []byte("in")},
+				{Val: []byte("{")},
+				{Val: []byte("USD}")},
+				{Val: []byte("a[1]")},
+				{Val: []byte("(")},
+				{Val: []byte("a|b)")},
+				{Val: []byte(`c:\`)},
+				{Kw: true, Val: []byte("Amount")},
+			},
+			false,
+		},
+		12: {
+			"err: unclosed keyword",
+			args{1, []byte("play {Name")},
+			nil,
+			true,
+		},
+		13: {
+			"err: stray brace",
+			args{1, []byte("play } {Name}")},
+			nil,
+			true,
+		},
 	}
 	for i, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,39 @@
+package parser
+
+import "unicode/utf8"
+
+// Tokenize splits an expression in tokens the same way ParseSample
+// splits the text of a sample, but it never fails: the characters of
+// the sample syntax, like braces and brackets, are read as any other
+// punctuation, so any text written by a user can be tokenized
+func Tokenize(expr []byte) []Token {
+	var tokens []Token
+	for i := 0; i < len(expr); {
+		r, size := utf8.DecodeRune(expr[i:])
+		if isSpace(r) {
+			i += size
+			continue
+		}
+		// a token starting with punctuation ends with it, like Punct
+		punct := isPunct(r)
+		start := i
+		for i < len(expr) {
+			r, size := utf8.DecodeRune(expr[i:])
+			if isSpace(r) || punct && !isPunct(r) {
+				break
+			}
+			i += size
+		}
+		tokens = append(tokens, Token{Val: expr[start:i]})
+	}
+	return tokens
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\r' || r == '\n'
+}
+
+// isPunct returns true if r isn't a space, an ascii letter or a digit
+func isPunct(r rune) bool {
+	return !isSpace(r) && !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want []string
+	}{
+		0: {"empty", "", nil},
+		1: {"spacing", " play \t King\r\nby  Lauren ", []string{"play", "King", "by", "Lauren"}},
+		2: {"punctuation", "hello, (world) ...ok", []string{"hello,", "(", "world)", "...", "ok"}},
+		3: {"braces", "print {x} and {", []string{"print", "{", "x}", "and", "{"}},
+		4: {"sample syntax", `a[b] (c|d) e\{`, []string{"a[b]", "(", "c|d)", `e\{`}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, tk := range Tokenize([]byte(tt.expr)) {
+				got = append(got, string(tk.Val))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Test#%d: Tokenize() = %q, want %q", i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenize_ParseSample(t *testing.T) {
+	// the text of a sample is tokenized like the expression it matches
+	tests := []struct {
+		sample, expr string
+	}{
+		0: {"hello, (world) ...ok {Name}", "hello, (world) ...ok"},
+		1: {`price in \{USD\} a\[1\] \(a\|b\) c:\\ {Amount}`, `price in {USD} a[1] (a|b) c:\`},
+		2: {"ñandú\témile {Name}", "ñandú\témile"},
+	}
+	for i, tt := range tests {
+		tokens, err := ParseSample(i, []byte(tt.sample))
+		if err != nil {
+			t.Fatalf("Test#%d: ParseSample() error = %v", i, err)
+		}
+		want := tokens[:len(tokens)-1]
+		if got := Tokenize([]byte(tt.expr)); !reflect.DeepEqual(got, want) {
+			t.Errorf("Test#%d: Tokenize() = %v, want %v", i, got, want)
+		}
+	}
+}
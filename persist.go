@@ -0,0 +1,293 @@
+package nlp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/cdipaolo/goml/base"
+	"github.com/cdipaolo/goml/text"
+)
+
+// saveVersion is the version of the format written by NL.Save
+const saveVersion = 1
+
+// Registry binds names to the types used to register models,
+// so saved models can be bound back to their types
+type Registry struct {
+	types map[string]registryEntry
+}
+
+type registryEntry struct {
+	i   interface{}
+	ops []ModelOption
+}
+
+// NewRegistry returns an empty *Registry
+func NewRegistry() *Registry { return &Registry{types: make(map[string]registryEntry)} }
+
+// Add adds i to the registry using the name of its type,
+// ops are applied to every model bound to i
+func (r *Registry) Add(i interface{}, ops ...ModelOption) error {
+	if i == nil {
+		return errors.New("can't add nil value to the registry")
+	}
+	tpy := reflect.TypeOf(i)
+	if tpy.Kind() != reflect.Struct || tpy.Name() == "" {
+		return fmt.Errorf("can't add %v to the registry, it must be a named struct", tpy)
+	}
+	if _, ok := r.types[tpy.Name()]; ok {
+		return fmt.Errorf("type %s is already in the registry", tpy.Name())
+	}
+	r.types[tpy.Name()] = registryEntry{i: i, ops: ops}
+	return nil
+}
+
+type savedNL struct {
+	Version    int             `json:"version"`
+	Models     []savedModel    `json:"models"`
+	NoMatch    []string        `json:"no_match,omitempty"`
+	Classifier json.RawMessage `json:"classifier"`
+}
+
+type savedModel struct {
+	Type         string           `json:"type"`
+	Samples      []string         `json:"samples"`
+	Expected     [][]savedVariant `json:"expected"`
+	TimeFormats  []string         `json:"time_formats"`
+	TimeLocation string           `json:"time_location"`
+	Separators   []string         `json:"separators"`
+	TrueWords    []string         `json:"true_words"`
+	FalseWords   []string         `json:"false_words"`
+	Fields       []savedField     `json:"fields"`
+	// TimeOffset is the offset in seconds of a fixed zone
+	// that isn't in the IANA database, like time.FixedZone
+	TimeOffset *int `json:"time_offset,omitempty"`
+}
+
+type savedVariant struct {
+	Optionals []bool      `json:"optionals,omitempty"`
+	Items     []savedItem `json:"items"`
+}
+
+type savedItem struct {
+	Limit bool   `json:"limit,omitempty"`
+	Value string `json:"value"`
+	Field string `json:"field,omitempty"`
+	Hint  string `json:"hint,omitempty"`
+	// Alternatives are the alternatives of a limit
+	Alternatives []string `json:"alternatives,omitempty"`
+}
+
+type savedField struct {
+	Index []int  `json:"index"`
+	Name  string `json:"name"`
+	// Enum and Synonyms are the enum of the field, set by
+	// its tag or by WithEnum
+	Enum     []string          `json:"enum,omitempty"`
+	Synonyms map[string]string `json:"synonyms,omitempty"`
+}
+
+// Save writes the learned state of nl to w, so it can
+// be restored with Load without learning again
+func (nl *NL) Save(w io.Writer) error {
+	st := nl.state.Load()
+	if st == nil {
+		return errors.New("call Learn before saving")
+	}
+	classifier, err := json.Marshal(st.naive)
+	if err != nil {
+		return fmt.Errorf("can't save classifier: %v", err)
+	}
+	s := savedNL{
+		Version:    saveVersion,
+		Classifier: classifier,
+	}
+	for id, m := range st.models {
+		// Load looks up the types of the models by name
+		if m.tpy.Name() == "" {
+			return fmt.Errorf("can't save model#%d, its type %v isn't named", id, m.tpy)
+		}
+		sm := savedModel{
+			Type:         m.tpy.Name(),
+			Expected:     make([][]savedVariant, len(m.expected)),
+			TimeFormats:  m.timeFormats,
+			TimeLocation: m.timeLocation.String(),
+			Separators:   m.separators,
+			TrueWords:    m.boolWords.truthy,
+			FalseWords:   m.boolWords.falsy,
+		}
+		if _, err := time.LoadLocation(sm.TimeLocation); err != nil {
+			offset, ok := fixedOffset(m.timeLocation)
+			if !ok {
+				return fmt.Errorf("can't save model#%d, its location %s isn't in the IANA database or a fixed zone", id, sm.TimeLocation)
+			}
+			sm.TimeOffset = &offset
+		}
+		for _, sample := range m.samples {
+			sm.Samples = append(sm.Samples, string(sample))
+		}
+		for sid, variants := range m.expected {
+			for _, v := range variants {
+				sv := savedVariant{Optionals: v.optionals}
+				for _, e := range v.items {
+					si := savedItem{
+						Limit: e.limit,
+						Value: string(e.value),
+						Field: e.field.name,
+						Hint:  e.hint,
+					}
+					for _, alt := range e.alternatives {
+						si.Alternatives = append(si.Alternatives, string(alt))
+					}
+					sv.Items = append(sv.Items, si)
+				}
+				sm.Expected[sid] = append(sm.Expected[sid], sv)
+			}
+		}
+		for _, f := range m.fields {
+			sf := savedField{Index: f.index, Name: f.name}
+			if f.enum != nil {
+				sf.Enum, sf.Synonyms = f.enum.values, f.enum.synonyms
+			}
+			sm.Fields = append(sm.Fields, sf)
+		}
+		s.Models = append(s.Models, sm)
+	}
+	for _, sample := range nl.noMatch {
+		s.NoMatch = append(s.NoMatch, string(sample))
+	}
+	return json.NewEncoder(w).Encode(s)
+}
+
+// fixedOffset returns the offset of loc if it's the same all
+// year round, as in the locations returned by time.FixedZone
+func fixedOffset(loc *time.Location) (int, bool) {
+	_, jan := time.Date(2000, time.January, 1, 0, 0, 0, 0, loc).Zone()
+	_, jul := time.Date(2000, time.July, 1, 0, 0, 0, 0, loc).Zone()
+	return jan, jan == jul
+}
+
+// Load restores a NL saved with NL.Save, the types of the saved
+// models are looked up by name in the registry. The returned *NL
+// is ready to process expressions
+func Load(r io.Reader, registry *Registry, ops ...Option) (*NL, error) {
+	if registry == nil {
+		return nil, errors.New("registry can't be nil")
+	}
+	var s savedNL
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("can't read saved NL: %v", err)
+	}
+	if s.Version != saveVersion {
+		return nil, fmt.Errorf("unsupported saved NL version %d", s.Version)
+	}
+	if len(s.Models) == 0 {
+		return nil, errors.New("saved NL doesn't contain any model")
+	}
+	nl := New(append([]Option{WithNoMatchSamples(s.NoMatch...)}, ops...)...)
+	st := &state{}
+	for id, sm := range s.Models {
+		mod, err := sm.restore(registry)
+		if err != nil {
+			return nil, fmt.Errorf("model#%d %v", id, err)
+		}
+		st.models = append(st.models, mod)
+	}
+	classes := len(st.models)
+	if len(s.NoMatch) > 0 {
+		classes++
+	}
+	st.naive = text.NewNaiveBayes(nil, uint8(classes), base.OnlyWordsAndNumbers)
+	if err := json.Unmarshal(s.Classifier, st.naive); err != nil {
+		return nil, fmt.Errorf("can't read saved classifier: %v", err)
+	}
+	if len(st.naive.Count) != classes {
+		return nil, fmt.Errorf("saved classifier has %d classes, want %d", len(st.naive.Count), classes)
+	}
+	st.naive.Output = nl.Output
+	// the learned models are never modified, Learn works with clones
+	nl.models = st.models
+	nl.state.Store(st)
+	return nl, nil
+}
+
+// restore binds sm to its type in the registry
+func (sm savedModel) restore(registry *Registry) (*model, error) {
+	entry, ok := registry.types[sm.Type]
+	if !ok {
+		return nil, fmt.Errorf("type %s isn't in the registry", sm.Type)
+	}
+	var loc *time.Location
+	if sm.TimeOffset != nil {
+		loc = time.FixedZone(sm.TimeLocation, *sm.TimeOffset)
+	} else {
+		var err error
+		if loc, err = time.LoadLocation(sm.TimeLocation); err != nil {
+			return nil, err
+		}
+	}
+	saved := func(m *model) error {
+		m.timeFormats = sm.TimeFormats
+		m.timeLocation = loc
+		m.separators = sm.Separators
+		if len(sm.TrueWords) > 0 {
+			m.boolWords = boolWords{sm.TrueWords, sm.FalseWords}
+		}
+		return nil
+	}
+	mod, err := newModel(entry.i, sm.Samples, append([]ModelOption{saved}, entry.ops...)...)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]*field, len(mod.fields))
+	for i := range mod.fields {
+		fields[mod.fields[i].name] = &mod.fields[i]
+	}
+	for _, sf := range sm.Fields {
+		f, ok := fields[sf.Name]
+		if !ok || !reflect.DeepEqual(f.index, sf.Index) {
+			return nil, fmt.Errorf("field %s of type %s has changed since it was saved", sf.Name, sm.Type)
+		}
+		switch {
+		case f.enum == nil && sf.Enum != nil:
+			// the options of the registry don't set the enum
+			if f.enum, err = newEnum(sf.Enum, sf.Synonyms); err != nil {
+				return nil, fmt.Errorf("field %s: %v", sf.Name, err)
+			}
+		case f.enum != nil && !f.enum.equal(sf.Enum, sf.Synonyms):
+			return nil, fmt.Errorf("enum of field %s has changed since it was saved", sf.Name)
+		}
+	}
+	if len(sm.Expected) != len(mod.samples) {
+		return nil, fmt.Errorf("saved model has %d learned samples, want %d", len(sm.Expected), len(mod.samples))
+	}
+	for sid, variants := range sm.Expected {
+		for _, sv := range variants {
+			v := variant{optionals: sv.Optionals}
+			for _, e := range sv.Items {
+				it := item{limit: e.Limit, value: []byte(e.Value)}
+				for _, alt := range e.Alternatives {
+					it.alternatives = append(it.alternatives, []byte(alt))
+				}
+				if !e.Limit {
+					f, ok := fields[e.Field]
+					if !ok {
+						return nil, fmt.Errorf("sample#%d: unknown field %q", sid, e.Field)
+					}
+					it.field, err = mod.hint(*f, e.Hint)
+					if err != nil {
+						return nil, fmt.Errorf("sample#%d: %v", sid, err)
+					}
+					it.hint = e.Hint
+				}
+				v.items = append(v.items, it)
+			}
+			mod.expected[sid] = append(mod.expected[sid], v)
+		}
+	}
+	return mod, nil
+}
@@ -0,0 +1,202 @@
+package nlp
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type persistSong struct {
+	Name       string
+	Artist     string
+	ReleasedAt time.Time
+}
+
+type persistTimer struct {
+	Dur time.Duration
+}
+
+func TestNL_Save(t *testing.T) {
+	nl := New(WithNoMatchSamples("what's the weather like"))
+
+	var buf bytes.Buffer
+	if err := nl.Save(&buf); err == nil {
+		t.Error("NL.Save() before learning must fail")
+	}
+
+	failTest(t, nl.RegisterModel(persistSong{}, []string{
+		"play {Name} by {Artist}",
+		"play {Name}",
+		"play something from {ReleasedAt}",
+		"(play|put on) {Name} [by {Artist}] released in {ReleasedAt:Jan 2006}",
+	}, WithTimeFormat("2006"), WithTimeLocation(time.UTC)))
+	failTest(t, nl.RegisterModel(persistTimer{}, []string{"set a timer for {Dur}", "timer {Dur}"}))
+	failTest(t, nl.Learn())
+	failTest(t, nl.Save(&buf))
+
+	registry := NewRegistry()
+	failTest(t, registry.Add(persistSong{}))
+	failTest(t, registry.Add(persistTimer{}))
+
+	loaded, err := Load(&buf, registry)
+	failTest(t, err)
+
+	for i, expr := range []string{
+		"hello play King by Lauren Aquilina",
+		"play something from 1999",
+		"play King released in May 1999",
+		"put on King by Lauren released in May 1999",
+		"set a timer for 4h2m",
+		"what's the weather like",
+	} {
+		want, wantErr := nl.Parse(expr)
+		got, err := loaded.Parse(expr)
+		if err != wantErr || !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d] loaded NL.Parse() = %+v, %v want %+v, %v", i, got, err, want, wantErr)
+		}
+	}
+
+	// a loaded NL can keep learning, just like nl learning every model again
+	type Alarm struct {
+		At string
+	}
+	alarmSamples := []string{
+		"wake me up at {At}",
+		"wake up call at {At}",
+		"alarm ringing at {At}",
+		"morning alarm {At}",
+	}
+	failTest(t, loaded.RegisterModel(Alarm{}, alarmSamples))
+	failTest(t, loaded.Learn())
+	failTest(t, nl.RegisterModel(Alarm{}, alarmSamples))
+	failTest(t, nl.Learn())
+	for i, expr := range []string{"wake me up at 7", "alarm ringing at noon", "play King"} {
+		if got, want := loaded.P(expr), nl.P(expr); !reflect.DeepEqual(got, want) {
+			t.Errorf("[%d] loaded NL.P() = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestNL_Save_TimeLocation(t *testing.T) {
+	nl := New()
+	failTest(t, nl.RegisterModel(persistSong{}, []string{"play something from {ReleasedAt}"},
+		WithTimeFormat("2006"), WithTimeLocation(time.FixedZone("EST5", -5*3600))))
+	failTest(t, nl.Learn())
+	var buf bytes.Buffer
+	failTest(t, nl.Save(&buf))
+
+	registry := NewRegistry()
+	failTest(t, registry.Add(persistSong{}))
+	loaded, err := Load(&buf, registry)
+	failTest(t, err)
+
+	want := time.Date(1999, 1, 1, 0, 0, 0, 0, time.FixedZone("EST5", -5*3600))
+	got := loaded.P("play something from 1999").(*persistSong).ReleasedAt
+	name, offset := got.Zone()
+	if !got.Equal(want) || name != "EST5" || offset != -5*3600 {
+		t.Errorf("loaded NL.P() = %v, want %v", got, want)
+	}
+
+	// anonymous structs can't be looked up in a registry
+	nl = New()
+	failTest(t, nl.RegisterModel(struct{ Name string }{}, []string{"play {Name}"}))
+	failTest(t, nl.Learn())
+	if err := nl.Save(&buf); err == nil {
+		t.Error("NL.Save() of an anonymous struct must fail")
+	}
+}
+
+func TestNL_Save_Enum(t *testing.T) {
+	type Clean struct {
+		Room string
+	}
+	enum := WithEnum("Room", []string{"kitchen", "garage"}, map[string]string{"cuisine": "kitchen"})
+	nl := New()
+	failTest(t, nl.RegisterModel(Clean{}, []string{"clean the {Room}"}, enum))
+	failTest(t, nl.Learn())
+	var buf bytes.Buffer
+	failTest(t, nl.Save(&buf))
+	saved := buf.String()
+
+	// the enum is restored even if the registry doesn't set it
+	registry := NewRegistry()
+	failTest(t, registry.Add(Clean{}))
+	loaded, err := Load(strings.NewReader(saved), registry)
+	failTest(t, err)
+	for i, expr := range []string{"clean the big garage", "clean the cuisine", "clean the moon"} {
+		want, wantErr := nl.Parse(expr)
+		got, err := loaded.Parse(expr)
+		if !reflect.DeepEqual(err, wantErr) || !reflect.DeepEqual(got.Value, want.Value) {
+			t.Errorf("[%d] loaded NL.Parse() = %+v, %v want %+v, %v", i, got.Value, err, want.Value, wantErr)
+		}
+	}
+
+	registry = NewRegistry()
+	failTest(t, registry.Add(Clean{}, WithEnum("Room", []string{"kitchen"}, nil)))
+	if _, err := Load(strings.NewReader(saved), registry); err == nil {
+		t.Error("Load() with a different enum must fail")
+	}
+	registry = NewRegistry()
+	failTest(t, registry.Add(Clean{}, enum))
+	if _, err := Load(strings.NewReader(saved), registry); err != nil {
+		t.Errorf("Load() with the same enum error = %v", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	nl := New()
+	failTest(t, nl.RegisterModel(persistTimer{}, []string{"timer {Dur}"}))
+	failTest(t, nl.Learn())
+	var buf bytes.Buffer
+	failTest(t, nl.Save(&buf))
+	saved := buf.String()
+
+	registry := NewRegistry()
+	failTest(t, registry.Add(persistTimer{}))
+
+	tests := []struct {
+		name     string
+		saved    string
+		registry *Registry
+		wantErr  bool
+	}{
+		0: {"valid", saved, registry, false},
+		1: {"nil registry", saved, nil, true},
+		2: {"empty registry", saved, NewRegistry(), true},
+		3: {"invalid json", "{", registry, true},
+		4: {"unsupported version", strings.Replace(saved, `"version":1`, `"version":99`, 1), registry, true},
+		5: {"changed field", strings.Replace(saved, `"index":[0]`, `"index":[3]`, 1), registry, true},
+		6: {"unknown field", strings.Replace(saved, `"field":"Dur"`, `"field":"Duration"`, 1), registry, true},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(tt.saved), tt.registry); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] Load() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegistry_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		i       interface{}
+		wantErr bool
+	}{
+		0: {"nil", nil, true},
+		1: {"non-struct", 1, true},
+		2: {"unnamed struct", struct{ Name string }{}, true},
+		3: {"struct", persistTimer{}, false},
+		4: {"already added", persistTimer{}, true},
+	}
+	registry := NewRegistry()
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Add(tt.i); (err != nil) != tt.wantErr {
+				t.Errorf("[%d] Registry.Add() error = %v, wantErr %v", i, err, tt.wantErr)
+			}
+		})
+	}
+}
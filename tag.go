@@ -0,0 +1,117 @@
+package nlp
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tagOptions are the options allowed inside the nlp struct tag,
+// options without value are mapped to false
+var tagOptions = map[string]bool{
+	"alias":    true,
+	"layout":   true,
+	"default":  true,
+	"true":     true,
+	"false":    true,
+	"enum":     true,
+	"synonyms": true,
+	"required": false,
+}
+
+// setTag sets the options of the nlp struct tag in f:
+//
+//	`nlp:"released,alias=year|date,layout=2006|Jan_2006,required,default=1999"`
+//	`nlp:"state,true=on|lit,false=off|dark"`
+//	`nlp:"room,enum=kitchen|bedroom,synonyms=bed room:bedroom|cuisine:kitchen"`
+//
+// the first element is the keyword used in the samples, if it's empty the
+// name of the field is used. Option values may contain commas.
+func (f *field) setTag(tag string) error {
+	if tag == "" {
+		return nil
+	}
+	opts := splitTag(tag)
+	if opts[0] != "" {
+		f.name = opts[0]
+	}
+	var values []string
+	var synonyms map[string]string
+	for _, opt := range opts[1:] {
+		key, val, hasVal := strings.Cut(opt, "=")
+		if _, ok := tagOptions[key]; !ok {
+			return fmt.Errorf("field %s: unknown option %q", f.name, key)
+		}
+		switch key {
+		case "alias":
+			f.aliases = strings.Split(val, "|")
+		case "layout":
+			f.layouts = strings.Split(val, "|")
+		case "default":
+			f.def = &val
+		case "required":
+			f.required = true
+		case "true", "false":
+			if f.boolWords == nil {
+				f.boolWords = &boolWords{}
+			}
+			if key == "true" {
+				f.boolWords.truthy = strings.Split(val, "|")
+			} else {
+				f.boolWords.falsy = strings.Split(val, "|")
+			}
+		case "enum":
+			values = strings.Split(val, "|")
+		case "synonyms":
+			synonyms = make(map[string]string)
+			for _, syn := range strings.Split(val, "|") {
+				s, v, ok := strings.Cut(syn, ":")
+				if !ok {
+					return fmt.Errorf("field %s: synonym %q must be synonym:value", f.name, syn)
+				}
+				synonyms[s] = v
+			}
+		}
+		if tagOptions[key] != hasVal {
+			if hasVal {
+				return fmt.Errorf("field %s: option %q doesn't take any value", f.name, key)
+			}
+			return fmt.Errorf("field %s: option %q needs a value", f.name, key)
+		}
+	}
+	for _, kw := range append([]string{f.name}, f.aliases...) {
+		if kw == "" || strings.IndexFunc(kw, func(r rune) bool { return unicode.IsSpace(r) || r == '{' || r == '}' || r == ':' }) != -1 {
+			return fmt.Errorf("field %s: invalid keyword %q", f.name, kw)
+		}
+	}
+	if synonyms != nil && values == nil {
+		return fmt.Errorf("field %s: synonyms can only be set with enum", f.name)
+	}
+	if values != nil {
+		e, err := newEnum(values, synonyms)
+		if err != nil {
+			return fmt.Errorf("field %s: %v", f.name, err)
+		}
+		f.enum = e
+	}
+	if f.required && f.def != nil {
+		return fmt.Errorf("field %s: a required field can't have a default value", f.name)
+	}
+	return nil
+}
+
+// splitTag splits tag by its commas, the commas that aren't followed by
+// a known option are considered part of the value of the previous option
+func splitTag(tag string) []string {
+	parts := strings.Split(tag, ",")
+	opts := parts[:1]
+	for _, p := range parts[1:] {
+		key, _, _ := strings.Cut(p, "=")
+		if _, ok := tagOptions[key]; ok || len(opts) == 1 {
+			opts = append(opts, p)
+			continue
+		}
+		opts[len(opts)-1] += "," + p
+	}
+	return opts
+}